@@ -2,12 +2,15 @@ module github.com/hitzhangjie/goenv
 
 go 1.24.1
 
-require github.com/spf13/cobra v1.10.2
+require (
+	github.com/google/go-github/v81 v81.0.0
+	github.com/spf13/cobra v1.10.2
+	golang.org/x/mod v0.30.0
+	golang.org/x/oauth2 v0.34.0
+)
 
 require (
-	github.com/google/go-github/v81 v81.0.0 // indirect
 	github.com/google/go-querystring v1.1.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
-	golang.org/x/oauth2 v0.34.0 // indirect
 )
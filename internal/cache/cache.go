@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/hitzhangjie/goenv/internal/config"
@@ -41,11 +42,7 @@ func SaveVersions(data *version.VersionsData) error {
 	}
 
 	// Ensure directory exists
-	root, err := config.GetGoenvRoot()
-	if err != nil {
-		return err
-	}
-	if err := config.EnsureDir(root); err != nil {
+	if err := config.EnsureDir(filepath.Dir(filePath)); err != nil {
 		return fmt.Errorf("failed to create goenv directory: %w", err)
 	}
 
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hitzhangjie/goenv/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt [goroot...]",
+	Short: "Adopt Go SDKs installed outside goenv",
+	Long: `Adopt existing Go SDKs into goenv instead of downloading them again.
+
+Without arguments, SDKs are discovered in ~/sdk (golang.org/dl), /usr/local/go
+and the go command's toolchain downloads in GOMODCACHE. The version is read
+from each GOROOT's VERSION file and the usual wrappers are generated.
+
+--mode controls how the SDK is brought in: link references it in place,
+hardlink shares its files (copying across devices), copy duplicates it and
+move relocates it into ~/.goenv/sdk.`,
+	RunE: runAdopt,
+}
+
+func init() {
+	adoptCmd.Flags().String("mode", installer.AdoptHardlink, "How to adopt SDKs: link, hardlink, copy or move")
+	adoptCmd.Flags().Bool("dry-run", false, "Only show what would be adopted")
+	adoptCmd.RegisterFlagCompletionFunc("mode", fixedValues(installer.AdoptHardlink, installer.AdoptCopy, installer.AdoptLink, installer.AdoptMove))
+}
+
+func runAdopt(cmd *cobra.Command, args []string) error {
+	mode, _ := cmd.Flags().GetString("mode")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	var candidates []installer.Candidate
+	if len(args) > 0 {
+		for _, goroot := range args {
+			v, err := installer.ReadGoRootVersion(goroot)
+			if err != nil {
+				return fmt.Errorf("%s is not a Go SDK: %w", goroot, err)
+			}
+			candidates = append(candidates, installer.Candidate{Version: v, Dir: goroot})
+		}
+	} else {
+		var err error
+		candidates, err = installer.DiscoverSDKs()
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(candidates) == 0 {
+		fmt.Println("No Go SDKs found to adopt.")
+		return nil
+	}
+
+	adopted := 0
+	for _, c := range candidates {
+		if _, err := installer.FindInstalled(c.Version); err == nil {
+			fmt.Printf("Skipping %s (%s): already installed\n", c.Version, c.Dir)
+			continue
+		}
+		if dryRun {
+			fmt.Printf("Would %s %s from %s\n", mode, c.Version, c.Dir)
+			continue
+		}
+		v, err := installer.Adopt(c.Dir, mode)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to adopt %s: %v\n", c.Dir, err)
+			continue
+		}
+		adopted++
+		fmt.Printf("Adopted %s from %s (%s)\n", v, c.Dir, mode)
+	}
+
+	if !dryRun {
+		fmt.Printf("Adopted %d Go version(s).\n", adopted)
+	}
+	return nil
+}
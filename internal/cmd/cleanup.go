@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"fmt"
+
+	"github.com/hitzhangjie/goenv/internal/config"
 	"github.com/hitzhangjie/goenv/internal/installer"
 	"github.com/spf13/cobra"
 )
@@ -8,13 +11,44 @@ import (
 var cleanupCmd = &cobra.Command{
 	Use:   "cleanup",
 	Short: "Clean up downloaded Go version archives",
-	Long:  "Remove all downloaded Go version archives from the downloads directory",
-	RunE:  runCleanup,
+	Long: `Remove downloaded Go version archives from the downloads directory, along
+with partial downloads left behind by interrupted installs.
+
+By default the archives of installed versions are kept, pass
+--keep-installed=false to remove them too. --keep N keeps the N most recent
+archives and --older-than, e.g. 30d or 12h, only removes archives older than that.
+
+With --caches, the build caches (GOCACHE and GOTESTCACHE) of all versions are
+removed as well, go rebuilds them as needed. Use --dry-run to see what would be
+removed and how much space that frees.`,
+	Args: cobra.NoArgs,
+	RunE: runCleanup,
+}
+
+func init() {
+	cleanupCmd.Flags().Bool("keep-installed", true, "Keep the archives of installed versions")
+	cleanupCmd.Flags().Int("keep", 0, "Keep the N most recent archives")
+	cleanupCmd.Flags().String("older-than", "", "Only remove archives older than this, e.g. 30d")
+	cleanupCmd.Flags().Bool("caches", false, "Also remove the build caches of all versions")
+	cleanupCmd.Flags().Bool("dry-run", false, "Show what would be removed without removing anything")
 }
 
 func runCleanup(cmd *cobra.Command, args []string) error {
-	if err := installer.CleanupDownloads(); err != nil {
-		return err
+	var opts installer.CleanupOptions
+	opts.KeepInstalled, _ = cmd.Flags().GetBool("keep-installed")
+	opts.Keep, _ = cmd.Flags().GetInt("keep")
+	opts.Caches, _ = cmd.Flags().GetBool("caches")
+	opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	if opts.Keep < 0 {
+		return fmt.Errorf("--keep must not be negative, got %d", opts.Keep)
+	}
+	if olderThan, _ := cmd.Flags().GetString("older-than"); olderThan != "" {
+		age, err := config.ParseAge(olderThan)
+		if err != nil {
+			return fmt.Errorf("--older-than: %w", err)
+		}
+		opts.OlderThan = age
 	}
-	return nil
+
+	return installer.Cleanup(opts)
 }
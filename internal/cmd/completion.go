@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"github.com/hitzhangjie/goenv/internal/cache"
+	"github.com/hitzhangjie/goenv/internal/installer"
+	"github.com/hitzhangjie/goenv/internal/shell"
+	"github.com/spf13/cobra"
+)
+
+// Completions only read local state, they must work offline and fast.
+
+// completeInstalled completes the version argument with installed versions.
+// goenv has no uninstall command yet, it should use this once it does.
+func completeInstalled(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return installedVersions(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeInstalledFlag completes a flag value with installed versions
+func completeInstalledFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return installedVersions(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeAvailable completes the version argument with cached, not yet installed versions, newest first
+func completeAvailable(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	data, err := cache.LoadVersions()
+	if err != nil || data == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	installed := make(map[string]bool)
+	for _, v := range installedVersions() {
+		installed[v] = true
+	}
+
+	var completions []string
+	for i := len(data.Groups) - 1; i >= 0; i-- {
+		versions := data.Groups[i].Versions
+		for j := len(versions) - 1; j >= 0; j-- {
+			if tag := versions[j].Tag; !installed[tag] {
+				completions = append(completions, tag)
+			}
+		}
+	}
+	// Keep the order above rather than letting the shell sort alphabetically
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeMinorLines completes a flag value with the cached minor lines, newest first
+func completeMinorLines(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	data, err := cache.LoadVersions()
+	if err != nil || data == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var completions []string
+	for i := len(data.Groups) - 1; i >= 0; i-- {
+		completions = append(completions, "go"+data.Groups[i].MajorMinor)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeShells completes the shell argument
+func completeShells(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return []string{shell.Bash, shell.Zsh, shell.Fish}, cobra.ShellCompDirectiveNoFileComp
+}
+
+// fixedValues completes a flag value from a fixed list
+func fixedValues(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func installedVersions() []string {
+	installed, err := installer.ListInstalled()
+	if err != nil {
+		return nil
+	}
+	var versions []string
+	for _, inst := range installed {
+		versions = append(versions, inst.Version)
+	}
+	return versions
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and change goenv settings",
+	Long: `Show and change the settings in config.json in the goenv root, which is
+~/.goenv unless GOENV_ROOT points elsewhere.
+
+Keys are dotted paths into the file, e.g. mirror, concurrency, cache.modcache
+or env.lines.go1.19.GOPROXY. String settings take the value as it is, others
+such as concurrency or tools are given in JSON.
+
+Settings that end up in the wrappers, like env or wrapper_mode, take effect
+for script wrappers after goenv fix.`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runConfigSet,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a setting, restoring its default",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigUnset,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Print all settings",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigList,
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the config file in $VISUAL or $EDITOR",
+	Long: `Open a copy of the config file in $VISUAL or $EDITOR (default vi). The file
+is only replaced if the edited copy is valid, otherwise the copy is kept so
+the changes are not lost.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigEdit,
+}
+
+func init() {
+	// Values may start with a dash, e.g. GOFLAGS=-race
+	configSetCmd.Flags().SetInterspersed(false)
+
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configEditCmd)
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return err
+	}
+	value, err := config.GetKey(settings, args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println(value)
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return err
+	}
+	if settings, err = config.SetKey(settings, args[0], args[1]); err != nil {
+		return err
+	}
+	return config.SaveSettings(settings)
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) error {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return err
+	}
+	if settings, err = config.UnsetKey(settings, args[0]); err != nil {
+		return err
+	}
+	return config.SaveSettings(settings)
+}
+
+func runConfigList(cmd *cobra.Command, args []string) error {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return err
+	}
+	list, err := config.ListKeys(settings)
+	if err != nil {
+		return err
+	}
+	for _, kv := range list {
+		fmt.Printf("%s=%s\n", kv.Key, kv.Value)
+	}
+	return nil
+}
+
+func runConfigEdit(cmd *cobra.Command, args []string) error {
+	path, err := config.GetConfigFile()
+	if err != nil {
+		return err
+	}
+	original, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		original = []byte("{\n}\n")
+	} else if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	tmp, err := os.CreateTemp("", "goenv-config-*.json")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	if err := os.WriteFile(tmp.Name(), original, 0644); err != nil {
+		return err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// Editors are often given with arguments, e.g. "code --wait"
+	edit := exec.Command("sh", "-c", editor+` "$1"`, "sh", tmp.Name())
+	edit.Stdin, edit.Stdout, edit.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := edit.Run(); err != nil {
+		return fmt.Errorf("editor %s failed, your changes are in %s: %w", editor, tmp.Name(), err)
+	}
+
+	edited, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(edited, original) {
+		os.Remove(tmp.Name())
+		fmt.Println("No changes.")
+		return nil
+	}
+	if _, err := config.ParseSettings(edited); err != nil {
+		return fmt.Errorf("invalid config, %s was not changed and your edits are in %s: %w", path, tmp.Name(), err)
+	}
+
+	if err := config.EnsureDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, edited, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	os.Remove(tmp.Name())
+	fmt.Printf("Updated %s\n", path)
+	return nil
+}
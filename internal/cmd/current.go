@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hitzhangjie/goenv/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+var currentCmd = &cobra.Command{
+	Use:   "current [dir]",
+	Short: "Show the Go version pinned for a directory",
+	Long: `Show the Go version the current (or given) directory asks for.
+
+GOENV_VERSION takes precedence. Otherwise goenv walks up from the directory and
+uses the first of .go-version, .tool-versions (asdf), the go.mod toolchain
+directive and the go.mod go directive it finds.
+
+With --required, only the version to install is printed, e.g.
+  goenv install $(goenv current --required)`,
+	Args: cobra.MaximumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	},
+	SilenceUsage: true,
+	RunE:         runCurrent,
+}
+
+func init() {
+	currentCmd.Flags().Bool("required", false, "Print the version to install, whether or not it is installed")
+	currentCmd.Flags().Bool("bare", false, "Print only the resolved version")
+}
+
+func runCurrent(cmd *cobra.Command, args []string) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	inst, pin, err := installer.FindPinned(dir)
+	if required, _ := cmd.Flags().GetBool("required"); required {
+		if pin == nil {
+			return err
+		}
+		fmt.Println(pin.Required())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if bare, _ := cmd.Flags().GetBool("bare"); bare {
+		fmt.Println(inst.Version)
+		return nil
+	}
+	fmt.Printf("%s (set by %s)\n", inst.Version, pin.Describe())
+	return nil
+}
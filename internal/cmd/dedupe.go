@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hitzhangjie/goenv/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Hardlink identical files across installed Go versions",
+	Long: `Consecutive patch releases share most of their files. dedupe compares the
+contents of the files in ~/.goenv/sdk and replaces duplicates with hardlinks to
+a single copy, which is made read-only since it is shared by several SDKs.
+
+SDKs linked into goenv, SDKs of the system store and files that are also
+hardlinked from outside goenv (goenv adopt --mode hardlink) are left alone.
+Installing over a deduplicated SDK replaces its files instead of writing through
+the links.
+
+Set auto_dedupe in ~/.goenv/config.json (goenv config set auto_dedupe true)
+to run dedupe after every install.`,
+	Args: cobra.NoArgs,
+	RunE: runDedupe,
+}
+
+func runDedupe(cmd *cobra.Command, args []string) error {
+	result, err := installer.Dedupe()
+	if err != nil {
+		return err
+	}
+	if result.Files == 0 {
+		fmt.Println("No duplicate files found.")
+		return nil
+	}
+	fmt.Printf("Linked %d duplicate files, saved %s\n", result.Files, installer.FormatBytes(result.Saved))
+	return nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hitzhangjie/goenv/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+var envFileCmd = &cobra.Command{
+	Use:   "env-file <version>",
+	Short: "Show or seed the go env -w settings file of a Go version",
+	Long: `The wrappers point GOENV at ~/.goenv/<version>/env, so go env -w only
+changes the settings of that version instead of those shared by every toolchain.
+
+Without flags the path of the file is printed. --copy-from global seeds it from
+the settings used outside goenv, e.g. ~/.config/go/env, --copy-from <version>
+from another version.
+Script wrappers created before GOENV was set need a goenv fix to pick it up.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeInstalled,
+	RunE:              runEnvFile,
+}
+
+func init() {
+	envFileCmd.Flags().String("copy-from", "", "Copy the settings of \"global\" or another installed version")
+	envFileCmd.Flags().Bool("force", false, "Replace an existing env file")
+	envFileCmd.RegisterFlagCompletionFunc("copy-from", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return append([]string{installer.GlobalEnvFile}, installedVersions()...), cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+func runEnvFile(cmd *cobra.Command, args []string) error {
+	inst, err := installer.FindInstalled(args[0])
+	if err != nil {
+		return err
+	}
+
+	from, _ := cmd.Flags().GetString("copy-from")
+	if from == "" {
+		path, err := installer.EnvFile(inst.Version)
+		if err != nil {
+			return err
+		}
+		fmt.Println(path)
+		return nil
+	}
+
+	force, _ := cmd.Flags().GetBool("force")
+	src, err := installer.SeedEnvFile(inst, from, force)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Copied %s to the env file of %s\n", src, inst.Version)
+	return nil
+}
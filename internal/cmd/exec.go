@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hitzhangjie/goenv/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec [<version>] -- <command> [args...]",
+	Short: "Run a command with plain go resolving to a Go version",
+	Long: `Run a command with a PATH in which go and gofmt resolve to the given version,
+and with the same GOROOT, GOPATH and cache settings as the go<version> wrapper.
+Like the wrappers, it sets GOTOOLCHAIN=local unless configured otherwise or
+exported, and warns if the module in the current directory needs a newer Go.
+
+This is meant for Makefiles, go generate and tools such as gopls or goreleaser
+that shell out to go. The command's exit status is passed through.
+
+Without a version, the version pinned for the current directory is used
+(see goenv current). With --verbose, the version and the directory of its SDK,
+the user's own or the shared system store, are printed to stderr first.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeInstalled,
+	SilenceUsage:      true,
+	SilenceErrors:     true,
+	RunE:              runExec,
+}
+
+func init() {
+	execCmd.Flags().BoolP("verbose", "v", false, "Print the Go version and where its SDK comes from to stderr")
+	// Everything after the version belongs to the command
+	execCmd.Flags().SetInterspersed(false)
+}
+
+// exitError reports the exit status of a command run by goenv
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// ExitCode returns the exit status to exit goenv with
+func (e *exitError) ExitCode() int {
+	return e.code
+}
+
+func runExec(cmd *cobra.Command, args []string) error {
+	var inst *installer.Installation
+	var err error
+	if cmd.ArgsLenAtDash() == 0 {
+		dir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		if inst, _, err = installer.FindPinned(dir); err != nil {
+			return err
+		}
+	} else {
+		if inst, err = installer.FindInstalled(args[0]); err != nil {
+			return err
+		}
+		args = args[1:]
+		// With flag parsing stopped at the version, "--" reaches us as an argument
+		if len(args) > 0 && args[0] == "--" {
+			args = args[1:]
+		}
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("no command given, usage: goenv %s", cmd.Use)
+	}
+
+	if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+		origin := "user"
+		if inst.System {
+			origin = "system"
+		}
+		fmt.Fprintf(os.Stderr, "goenv: using %s from %s (%s)\n", inst.Version, inst.Dir, origin)
+	}
+
+	env, cleanup, err := installer.Environ(inst)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if dir, err := os.Getwd(); err == nil {
+		if mismatch := installer.FindToolchainMismatch(inst, dir, env); mismatch != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", mismatch)
+		}
+	}
+
+	code, err := installer.Run(env, args[0], args[1:]...)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return &exitError{code: code}
+	}
+	return nil
+}
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"fmt"
+
+	"github.com/hitzhangjie/goenv/internal/config"
 	"github.com/hitzhangjie/goenv/internal/installer"
 	"github.com/spf13/cobra"
 )
@@ -8,10 +11,56 @@ import (
 var fixCmd = &cobra.Command{
 	Use:   "fix",
 	Short: "Regenerate wrapper scripts for all installed Go versions",
-	Long:  "Regenerate wrapper scripts for all installed Go versions to apply the latest environment variable settings",
-	RunE:  runFix,
+	Long: `Regenerate wrapper scripts for all installed Go versions to apply the latest environment variable settings
+
+Tools installed with go<version> install land in ~/.goenv/<version>/bin, fix
+also creates shims for them in ~/.goenv/bin, named like gopls@go1.22.5 (set
+tool_shim_pattern in ~/.goenv/config.json to change this). They run with the
+environment of their version, and with its go first on PATH.
+
+Wrappers are bash scripts by default. Use --mode shim to make them symlinks to
+the goenv binary instead, which runs the right go command based on the name it
+was invoked as; they break if the binary is moved or removed, so only use them
+with goenv installed in a stable place. --mode portable makes scripts that
+locate the goenv root relative to themselves, so the tree keeps working when it
+is moved or copied elsewhere.
+
+Wrappers set GOTOOLCHAIN=local unless it is configured or exported. Shim
+wrappers, like goenv exec, warn before running go when the module needs a newer
+Go. Script and portable wrappers do not check on every run, the shell hook (see
+goenv hook) warns when entering such a module instead.
+
+Script wrappers embed the environment, run fix after editing the env section
+of ~/.goenv/config.json, e.g.
+
+  {
+    "env": {
+      "global":   {"GOPRIVATE": "git.example.com"},
+      "lines":    {"go1.19": {"GOPROXY": "https://legacy-proxy.example.com"}},
+      "versions": {"go1.22.5": {"CGO_ENABLED": "0"}}
+    }
+  }`,
+	RunE: runFix,
+}
+
+var fixMode string
+
+func init() {
+	fixCmd.Flags().StringVar(&fixMode, "mode", "", fmt.Sprintf("Switch the wrapper mode (%s, %s or %s)", config.WrapperShim, config.WrapperScript, config.WrapperPortable))
+	fixCmd.RegisterFlagCompletionFunc("mode", fixedValues(config.WrapperShim, config.WrapperScript, config.WrapperPortable))
 }
 
 func runFix(cmd *cobra.Command, args []string) error {
+	if fixMode != "" {
+		settings, err := config.LoadSettings()
+		if err != nil {
+			return err
+		}
+		settings.WrapperMode = fixMode
+		if err := config.SaveSettings(settings); err != nil {
+			return err
+		}
+		fmt.Printf("Wrapper mode set to %s\n", fixMode)
+	}
 	return installer.FixScripts()
 }
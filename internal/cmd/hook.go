@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+	"github.com/hitzhangjie/goenv/internal/installer"
+	"github.com/hitzhangjie/goenv/internal/project"
+	"github.com/hitzhangjie/goenv/internal/shell"
+	"github.com/spf13/cobra"
+)
+
+// shimDirEnv remembers which shim directory the hook put on PATH
+const shimDirEnv = "_GOENV_SHIM_DIR"
+
+// hookKeyEnv remembers what the hook last resolved, so hook-env stays quiet until it changes
+const hookKeyEnv = "_GOENV_HOOK_KEY"
+
+var hookCmd = &cobra.Command{
+	Use:   "hook bash|zsh|fish",
+	Short: "Print a shell hook switching go on directory change",
+	Long: `Print a hook that switches plain go and gofmt to the version pinned for the
+current directory (see goenv current) whenever you change directories:
+
+  eval "$(goenv hook zsh)"     # ~/.zshrc
+  eval "$(goenv hook bash)"    # ~/.bashrc
+  goenv hook fish | source     # ~/.config/fish/config.fish
+
+The hook checks on every prompt, but only looks up the pinned version when the
+directory, a pin file on the way up from it or the installed versions changed,
+so editing .go-version or installing the pinned version takes effect right
+away while other prompts only cost a few stat calls. When it switches, it also
+warns if the module needs a newer Go than the selected version. Outside
+pinned projects the shim directory is removed from PATH, so the default set
+with goenv use applies again.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeShells,
+	RunE:              runHook,
+}
+
+var hookEnvCmd = &cobra.Command{
+	Use:    "hook-env bash|zsh|fish",
+	Short:  "Print the environment for the current directory, used by goenv hook",
+	Args:   cobra.ExactArgs(1),
+	Hidden: true,
+	RunE:   runHookEnv,
+}
+
+var direnvCmd = &cobra.Command{
+	Use:   "direnv",
+	Short: "Print .envrc statements putting the pinned version on PATH",
+	Long: `Print direnv statements that put go and gofmt of the version pinned for the
+current directory on PATH. Add this to the project's .envrc:
+
+  eval "$(goenv direnv)"`,
+	Args: cobra.NoArgs,
+	RunE: runDirenv,
+}
+
+func runHook(cmd *cobra.Command, args []string) error {
+	if err := shell.Check(args[0]); err != nil {
+		return err
+	}
+	goenv, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	fmt.Print(shell.Hook(args[0], goenv))
+	return nil
+}
+
+func runHookEnv(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := shell.Check(name); err != nil {
+		return err
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	key := hookKey(dir)
+	if key == os.Getenv(hookKeyEnv) {
+		return nil
+	}
+	fmt.Println(shell.Export(name, hookKeyEnv, key))
+
+	shimDir, inst, _, err := pinnedShimDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "goenv: %v\n", err)
+	}
+	// Script wrappers cannot afford this check on every run, warn when entering the module instead
+	if inst != nil {
+		if mismatch, err := installer.WrapperToolchainMismatch(inst, dir); err == nil && mismatch != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", mismatch)
+		}
+	}
+
+	shimsRoot, err := config.GetShimsDir()
+	if err != nil {
+		return err
+	}
+
+	// Swap the previous shim directory for the new one
+	var entries []string
+	if shimDir != "" {
+		entries = append(entries, shimDir)
+	}
+	previous := os.Getenv(shimDirEnv)
+	for _, entry := range filepath.SplitList(os.Getenv("PATH")) {
+		if entry == previous || strings.HasPrefix(entry, shimsRoot+string(filepath.Separator)) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	fmt.Println(shell.SetPath(name, entries))
+	if shimDir != "" {
+		fmt.Println(shell.Export(name, shimDirEnv, shimDir))
+	} else if previous != "" {
+		fmt.Println(shell.Unset(name, shimDirEnv))
+	}
+	return nil
+}
+
+// hookKey describes what the result of hook-env depends on, without reading any
+// file so that unchanged prompts stay cheap: the directory, GOENV_VERSION, the
+// files a pin could come from and when they changed, and when versions were
+// installed or removed
+func hookKey(dir string) string {
+	parts := []string{dir, os.Getenv(config.VersionEnv)}
+	for d := dir; ; d = filepath.Dir(d) {
+		for _, name := range project.PinFiles {
+			path := filepath.Join(d, name)
+			if t := modTime(path); t != "" {
+				parts = append(parts, path, t)
+			}
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	if sdkDir, err := config.GetSDKDir(); err == nil {
+		parts = append(parts, modTime(sdkDir))
+	}
+	if systemRoot, err := config.GetSystemRoot(); err == nil && systemRoot != "" {
+		parts = append(parts, modTime(filepath.Join(systemRoot, config.SDKDir)))
+	}
+	return strings.Join(parts, "|")
+}
+
+// modTime returns the modification time of path, or "" if it does not exist
+func modTime(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return strconv.FormatInt(info.ModTime().UnixNano(), 10)
+}
+
+func runDirenv(cmd *cobra.Command, args []string) error {
+	shimDir, _, pin, err := pinnedShimDir()
+	if pin != nil && pin.Kind != project.KindEnv {
+		fmt.Printf("watch_file %s\n", shell.Quote(shell.Bash, pin.Source))
+	}
+	if err != nil {
+		fmt.Printf("log_error %s\n", shell.Quote(shell.Bash, "goenv: "+err.Error()))
+		return nil
+	}
+	if shimDir == "" {
+		fmt.Printf("log_status %s\n", shell.Quote(shell.Bash, "goenv: no Go version pinned"))
+		return nil
+	}
+	fmt.Printf("PATH_add %s\n", shell.Quote(shell.Bash, shimDir))
+	return nil
+}
+
+// pinnedShimDir returns the shim directory of the version pinned for the working
+// directory and its installation. All are empty without error if nothing is pinned.
+func pinnedShimDir() (string, *installer.Installation, *project.Pin, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	inst, pin, err := installer.FindPinned(dir)
+	if pin == nil {
+		// Nothing pinned is not an error for hooks, unless resolving failed
+		if err != nil && !errors.Is(err, installer.ErrNotPinned) {
+			return "", nil, nil, err
+		}
+		return "", nil, nil, nil
+	}
+	if err != nil {
+		return "", nil, pin, err
+	}
+	shimDir, err := installer.EnsureShimDir(inst)
+	return shimDir, inst, pin, err
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+	"github.com/hitzhangjie/goenv/internal/shell"
+	"github.com/spf13/cobra"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init [bash|zsh|fish]",
+	Short: "Print shell setup for goenv",
+	Long: `Print the snippet that sets up goenv in your shell: ~/.goenv/bin on PATH,
+shell completion and, with --hook, automatic switching on cd (see goenv hook).
+
+Source it from your rc file, or let goenv do that:
+
+  goenv init --install            # append a marked block to the rc file
+  goenv init --uninstall          # remove it again
+
+The shell defaults to $SHELL.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeShells,
+	RunE:              runInit,
+}
+
+func init() {
+	initCmd.Flags().Bool("hook", false, "Include the directory change hook")
+	initCmd.Flags().Bool("install", false, "Add the setup to the shell's rc file")
+	initCmd.Flags().Bool("uninstall", false, "Remove the setup from the shell's rc file")
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	var name string
+	if len(args) > 0 {
+		name = args[0]
+		if err := shell.Check(name); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if name, err = shell.Detect(); err != nil {
+			return err
+		}
+	}
+
+	hook, _ := cmd.Flags().GetBool("hook")
+	install, _ := cmd.Flags().GetBool("install")
+	uninstall, _ := cmd.Flags().GetBool("uninstall")
+
+	goenv, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case install && uninstall:
+		return fmt.Errorf("--install and --uninstall are mutually exclusive")
+	case install:
+		rcFile, err := shell.RCFile(name)
+		if err != nil {
+			return err
+		}
+		changed, err := shell.InstallBlock(rcFile, shell.RCBlock(name, goenv, hook))
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %w", rcFile, err)
+		}
+		if changed {
+			fmt.Printf("Added goenv setup to %s, open a new shell to apply it.\n", rcFile)
+		} else {
+			fmt.Printf("goenv setup in %s is up to date.\n", rcFile)
+		}
+		return nil
+	case uninstall:
+		rcFile, err := shell.RCFile(name)
+		if err != nil {
+			return err
+		}
+		changed, err := shell.RemoveBlock(rcFile)
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %w", rcFile, err)
+		}
+		if changed {
+			fmt.Printf("Removed goenv setup from %s.\n", rcFile)
+		} else {
+			fmt.Printf("No goenv setup found in %s.\n", rcFile)
+		}
+		return nil
+	}
+
+	binDir, err := config.GetBinDir()
+	if err != nil {
+		return err
+	}
+	fmt.Print(shell.Init(name, goenv, binDir, hook))
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/hitzhangjie/goenv/internal/cache"
+	"github.com/hitzhangjie/goenv/internal/config"
 	"github.com/hitzhangjie/goenv/internal/installer"
 	"github.com/hitzhangjie/goenv/internal/version"
 	"github.com/spf13/cobra"
@@ -12,21 +13,94 @@ import (
 var installCmd = &cobra.Command{
 	Use:   "install <version>",
 	Short: "Install a Go version",
-	Long:  "Download and install a specific Go version",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runInstall,
+	Long: `Download and install a specific Go version.
+
+With --git, build a development toolchain from a local clone of the go
+repository instead. The result is installed as godev-<shortsha>.
+
+A version with a variant suffix, e.g. go1.22.5+boring, is built from the
+release's source archive with the given --experiment values and --patch
+series applied, and installed alongside the vanilla SDK.
+
+With --source proxy, the golang.org/toolchain module zip is fetched from
+GOPROXY (or --proxy, which may be a file:// directory) and verified against
+--sum or the checksum database (GOSUMDB) before it is unpacked. The checksum
+database is still contacted with a file:// proxy, pass --sum (or set
+GOSUMDB=off) to install without network access.
+
+With --system, the version is installed into the shared system store,
+$GOENV_SYSTEM_ROOT or /opt/goenv, for all users of the machine. Members of the
+store's group can install there, the installed SDKs are made read-only. No
+wrappers are written into the store, every user's goenv creates its own: the
+installing user gets them right away, other users run goenv fix.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeAvailable,
+	RunE:              runInstall,
 }
 
-func runInstall(cmd *cobra.Command, args []string) error {
+func init() {
+	installCmd.Flags().String("git", "", "Build from a local clone of the go repository")
+	installCmd.Flags().String("ref", "HEAD", "Commit, branch or tag to build with --git")
+	installCmd.Flags().StringSlice("experiment", nil, "GOEXPERIMENT value baked into a variant build (repeatable)")
+	installCmd.Flags().StringArray("patch", nil, "Patch file or directory of patches applied to a variant build (repeatable)")
+	installCmd.Flags().String("source", "", "Where to get a release from: download or proxy (default: the source setting, download)")
+	installCmd.Flags().String("proxy", "", "GOPROXY URL for --source proxy (default: the proxy setting or first entry of $GOPROXY)")
+	installCmd.Flags().String("sum", "", "Expected h1: hash or go.sum line for --source proxy")
+	installCmd.Flags().Bool("system", false, "Install into the shared system store")
+	installCmd.Flags().String("bootstrap", "", "Installed Go version used to bootstrap source builds (default: newest installed)")
+	installCmd.RegisterFlagCompletionFunc("bootstrap", completeInstalledFlag)
+	installCmd.RegisterFlagCompletionFunc("source", fixedValues(installer.SourceDownload, installer.SourceProxy))
+}
+
+func runInstall(cmd *cobra.Command, args []string) (err error) {
+	if system, _ := cmd.Flags().GetBool("system"); system {
+		root, err := installer.UseSystemStore()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Installing into the system store %s\n", root)
+		defer func() {
+			if err == nil {
+				err = installer.SealSystemStore()
+			}
+			if err == nil {
+				err = installer.LeaveSystemStore()
+			}
+		}()
+	}
+
+	gitRepo, _ := cmd.Flags().GetString("git")
+	if gitRepo != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("--git builds are named after their commit and take no version argument")
+		}
+		ref, _ := cmd.Flags().GetString("ref")
+		bootstrap, _ := cmd.Flags().GetString("bootstrap")
+		if err := installer.InstallFromGit(gitRepo, ref, bootstrap); err != nil {
+			return fmt.Errorf("installation failed: %w", err)
+		}
+		return nil
+	}
+
+	if len(args) != 1 {
+		return fmt.Errorf("requires a version argument, e.g. goenv install go1.22.5")
+	}
 	versionStr := version.NormalizeVersion(args[0])
 
+	experiments, _ := cmd.Flags().GetStringSlice("experiment")
+	patches, _ := cmd.Flags().GetStringArray("patch")
+	base, variant := version.SplitVariant(versionStr)
+	if variant == "" && (len(experiments) > 0 || len(patches) > 0) {
+		return fmt.Errorf("--experiment and --patch require a variant name, e.g. %s+custom", versionStr)
+	}
+
 	// Verify version exists in cache
 	cachedData, err := cache.LoadVersions()
 	if err == nil && cachedData != nil {
 		found := false
 		for _, group := range cachedData.Groups {
 			for _, v := range group.Versions {
-				if v.Tag == versionStr {
+				if v.Tag == base {
 					found = true
 					break
 				}
@@ -36,14 +110,47 @@ func runInstall(cmd *cobra.Command, args []string) error {
 			}
 		}
 		if !found {
-			fmt.Printf("Warning: Version %s not found in cached versions list.\n", versionStr)
+			fmt.Printf("Warning: Version %s not found in cached versions list.\n", base)
 			fmt.Println("You may want to run 'goenv versions --update' first to refresh the list.")
 		}
 	}
 
-	// Install
-	if err := installer.Install(versionStr); err != nil {
-		return fmt.Errorf("installation failed: %w", err)
+	if variant != "" {
+		bootstrap, _ := cmd.Flags().GetString("bootstrap")
+		if err := installer.InstallVariant(versionStr, experiments, patches, bootstrap); err != nil {
+			return fmt.Errorf("installation failed: %w", err)
+		}
+		return nil
+	}
+
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return err
+	}
+	source, _ := cmd.Flags().GetString("source")
+	if source == "" {
+		source = settings.Source
+	}
+	switch source {
+	case installer.SourceDownload:
+		// Install
+		if err := installer.Install(versionStr); err != nil {
+			return fmt.Errorf("installation failed: %w", err)
+		}
+	case installer.SourceProxy:
+		opts := installer.DefaultProxyOptions()
+		if settings.Proxy != "" {
+			opts.Proxy = settings.Proxy
+		}
+		if proxy, _ := cmd.Flags().GetString("proxy"); proxy != "" {
+			opts.Proxy = proxy
+		}
+		opts.Sum, _ = cmd.Flags().GetString("sum")
+		if err := installer.InstallFromProxy(versionStr, opts); err != nil {
+			return fmt.Errorf("installation failed: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown install source %q, want %s or %s", source, installer.SourceDownload, installer.SourceProxy)
 	}
 
 	return nil
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+	"github.com/hitzhangjie/goenv/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+var migrateLayoutCmd = &cobra.Command{
+	Use:   "migrate-layout [xdg|classic]",
+	Short: "Move goenv files between ~/.goenv and the XDG base directories",
+	Long: `goenv keeps everything in ~/.goenv by default (the classic layout). The xdg
+layout splits it by the XDG base directory spec:
+
+  $XDG_CONFIG_HOME/goenv  config.json
+  $XDG_CACHE_HOME/goenv   version list, downloads, builds and build caches
+  $XDG_DATA_HOME/goenv    SDKs, wrappers, receipts and per-version GOPATHs
+
+migrate-layout moves an existing tree to the given layout (default xdg) and
+regenerates the wrappers. goenv detects the layout from which directory exists,
+GOENV_LAYOUT=xdg makes a new installation start out in the xdg layout.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: fixedValues(config.LayoutXDG, config.LayoutClassic),
+	RunE:              runMigrateLayout,
+}
+
+func runMigrateLayout(cmd *cobra.Command, args []string) error {
+	name := config.LayoutXDG
+	if len(args) > 0 {
+		name = args[0]
+	}
+	if err := installer.MigrateLayout(name); err != nil {
+		return err
+	}
+
+	binDir, err := config.GetBinDir()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrappers are now in %s, run 'goenv init --install' to update your shell setup.\n", binDir)
+	return nil
+}
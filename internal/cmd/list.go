@@ -2,59 +2,72 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 
-	"github.com/hitzhangjie/goenv/internal/config"
+	"github.com/hitzhangjie/goenv/internal/installer"
 	"github.com/spf13/cobra"
 )
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List installed Go versions",
-	Long:  "Display all Go versions that have been installed",
-	RunE:  runList,
+	Long: `Display all Go versions that have been installed.
+
+Versions marked [system] come from the shared system store, $GOENV_SYSTEM_ROOT
+or /opt/goenv. A version installed by the user takes precedence over the same
+version in the store.`,
+	RunE: runList,
 }
 
 func runList(cmd *cobra.Command, args []string) error {
-	sdkDir, err := config.GetSDKDir()
+	installedVersions, err := installer.ListInstalled()
 	if err != nil {
 		return err
 	}
 
-	// Check if SDK directory exists
-	if _, err := os.Stat(sdkDir); os.IsNotExist(err) {
+	if len(installedVersions) == 0 {
 		fmt.Println("No Go versions installed.")
 		return nil
 	}
 
-	// Read all directories in SDK
-	entries, err := os.ReadDir(sdkDir)
-	if err != nil {
-		return fmt.Errorf("failed to read SDK directory: %w", err)
+	fmt.Println("Installed Go versions:")
+	for _, inst := range installedVersions {
+		fmt.Printf("  - %s%s%s\n", inst.Version, describeOrigin(&inst), describeReceipt(inst.Version))
 	}
 
-	var installedVersions []string
-	for _, entry := range entries {
-		if entry.IsDir() && strings.HasPrefix(entry.Name(), "go") {
-			// Verify it's a valid installation by checking for bin/go
-			goBin := filepath.Join(sdkDir, entry.Name(), "bin", "go")
-			if _, err := os.Stat(goBin); err == nil {
-				installedVersions = append(installedVersions, entry.Name())
-			}
-		}
-	}
+	return nil
+}
 
-	if len(installedVersions) == 0 {
-		fmt.Println("No Go versions installed.")
-		return nil
+// describeOrigin marks versions from the system store
+func describeOrigin(inst *installer.Installation) string {
+	if inst.System {
+		return " [system]"
 	}
+	return ""
+}
 
-	fmt.Println("Installed Go versions:")
-	for _, v := range installedVersions {
-		fmt.Printf("  - %s\n", v)
+// describeReceipt returns extra details about how a version was installed
+func describeReceipt(v string) string {
+	receipt, err := installer.LoadReceipt(v)
+	if err != nil || receipt == nil {
+		return ""
 	}
-
-	return nil
+	switch receipt.Source {
+	case installer.SourceGit:
+		return fmt.Sprintf(" (git %s, ref %s)", receipt.GitCommit, receipt.GitRef)
+	case installer.SourceVariant:
+		details := []string{"built from " + receipt.BaseVersion}
+		if len(receipt.Experiments) > 0 {
+			details = append(details, "GOEXPERIMENT="+strings.Join(receipt.Experiments, ","))
+		}
+		for _, p := range receipt.Patches {
+			details = append(details, "patch "+p.Name)
+		}
+		return " (" + strings.Join(details, ", ") + ")"
+	case installer.SourceAdopt:
+		return fmt.Sprintf(" (adopted from %s, %s)", receipt.Origin, receipt.Mode)
+	case installer.SourceMigrate:
+		return fmt.Sprintf(" (migrated from %s, %s)", receipt.Origin, receipt.Mode)
+	}
+	return ""
 }
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hitzhangjie/goenv/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate --from gvm|asdf|goenv-classic",
+	Short: "Import Go versions from another version manager",
+	Long: `Import the Go toolchains of gvm, asdf or goenv-classic (syndbg/goenv).
+
+Toolchains are hardlinked (or copied/linked, see --mode) into goenv and get
+the usual wrappers. With --projects, per-project version files found below
+the given directories are translated to .go-version files goenv reads.
+The other manager's files are never modified.
+
+goenv-classic is looked for in ~/.goenv/versions. If goenv itself uses
+~/.goenv, pass goenv-classic's root with --root.`,
+	Args: cobra.NoArgs,
+	RunE: runMigrate,
+}
+
+func init() {
+	migrateCmd.Flags().String("from", "", "Version manager to migrate from: gvm, asdf or goenv-classic")
+	migrateCmd.Flags().String("root", "", "Root directory of the version manager (default: its standard location)")
+	migrateCmd.Flags().String("mode", installer.AdoptHardlink, "How to import toolchains: link, hardlink or copy")
+	migrateCmd.Flags().StringArray("projects", nil, "Directory to scan for per-project version files (repeatable)")
+	migrateCmd.Flags().Bool("dry-run", false, "Only show what would be migrated")
+	migrateCmd.MarkFlagRequired("from")
+	migrateCmd.RegisterFlagCompletionFunc("from", fixedValues(installer.ManagerGVM, installer.ManagerAsdf, installer.ManagerClassic))
+	migrateCmd.RegisterFlagCompletionFunc("mode", fixedValues(installer.AdoptHardlink, installer.AdoptCopy, installer.AdoptLink))
+}
+
+func runMigrate(cmd *cobra.Command, args []string) error {
+	from, _ := cmd.Flags().GetString("from")
+	root, _ := cmd.Flags().GetString("root")
+	mode, _ := cmd.Flags().GetString("mode")
+	projects, _ := cmd.Flags().GetStringArray("projects")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	manager, err := installer.NewManager(from, root)
+	if err != nil {
+		return err
+	}
+	if mode == installer.AdoptMove {
+		return fmt.Errorf("migrate never modifies %s's files, use link, hardlink or copy", manager.Name)
+	}
+
+	candidates, problems, err := manager.SDKs()
+	if err != nil {
+		return err
+	}
+
+	imported := 0
+	for _, c := range candidates {
+		if _, err := installer.FindInstalled(c.Version); err == nil {
+			fmt.Printf("Skipping %s (%s): already installed\n", c.Version, c.Dir)
+			continue
+		}
+		if dryRun {
+			fmt.Printf("Would %s %s from %s\n", mode, c.Version, c.Dir)
+			continue
+		}
+		if err := manager.Import(c, mode); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", c.Dir, err))
+			continue
+		}
+		imported++
+		fmt.Printf("Imported %s from %s (%s)\n", c.Version, c.Dir, mode)
+	}
+
+	for _, dir := range projects {
+		pins, err := manager.FindProjectPins(dir)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", dir, err))
+			continue
+		}
+		for _, pin := range pins {
+			if pin.Version == "" {
+				problems = append(problems, fmt.Sprintf("%s: version %q has no goenv equivalent", pin.File, pin.Raw))
+				continue
+			}
+			status := "not installed"
+			if _, err := installer.FindInstalled(pin.Version); err == nil {
+				status = "installed"
+			}
+			if dryRun {
+				fmt.Printf("%s: %s -> %s (%s)\n", pin.File, pin.Raw, pin.Version, status)
+				continue
+			}
+			written, err := pin.Translate()
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", pin.File, err))
+				continue
+			}
+			if written != "" {
+				fmt.Printf("%s: %s -> %s (%s), wrote %s\n", pin.File, pin.Raw, pin.Version, status, written)
+			} else {
+				fmt.Printf("%s: %s -> %s (%s)\n", pin.File, pin.Raw, pin.Version, status)
+			}
+		}
+	}
+
+	if !dryRun {
+		fmt.Printf("Imported %d Go version(s) from %s.\n", imported, manager.Name)
+	}
+	if len(problems) > 0 {
+		fmt.Println("Could not migrate:")
+		for _, p := range problems {
+			fmt.Printf("  - %s\n", p)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+	"github.com/hitzhangjie/goenv/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+var relocateCmd = &cobra.Command{
+	Use:   "relocate <newroot>",
+	Short: "Move the goenv root to another directory",
+	Long: `Move the goenv root, ~/.goenv or $GOENV_ROOT, to a new directory, e.g. a
+larger disk. Symlinks, receipts and shared cache settings that point into the
+old tree are updated and the wrappers are regenerated.
+
+Afterwards, goenv has to be told about the new root with GOENV_ROOT, unless it
+is ~/.goenv. With wrapper_mode set to portable (goenv fix --mode portable) the
+wrappers themselves keep working wherever the tree is moved.
+
+Moving to another file system copies the tree, which turns files shared by
+goenv dedupe back into separate copies; run goenv dedupe again afterwards.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRelocate,
+}
+
+func runRelocate(cmd *cobra.Command, args []string) error {
+	if err := installer.Relocate(args[0]); err != nil {
+		return err
+	}
+
+	root, err := config.GetGoenvRoot()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("goenv now lives in %s.\n", root)
+	if home, err := os.UserHomeDir(); err != nil || root != filepath.Join(home, config.GoenvDir) {
+		fmt.Printf("Add 'export %s=%s' to your shell profile.\n", config.RootEnv, root)
+	}
+	fmt.Println("Run 'goenv init --install' to update your shell setup.")
+	return nil
+}
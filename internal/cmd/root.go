@@ -21,4 +21,21 @@ func init() {
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(cleanupCmd)
 	rootCmd.AddCommand(fixCmd)
+	rootCmd.AddCommand(adoptCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(execCmd)
+	rootCmd.AddCommand(shellCmd)
+	rootCmd.AddCommand(currentCmd)
+	rootCmd.AddCommand(useCmd)
+	rootCmd.AddCommand(hookCmd)
+	rootCmd.AddCommand(hookEnvCmd)
+	rootCmd.AddCommand(direnvCmd)
+	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(envFileCmd)
+	rootCmd.AddCommand(shareCacheCmd)
+	rootCmd.AddCommand(toolsCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(migrateLayoutCmd)
+	rootCmd.AddCommand(relocateCmd)
+	rootCmd.AddCommand(dedupeCmd)
 }
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+	"github.com/hitzhangjie/goenv/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+var shareCacheCmd = &cobra.Command{
+	Use:   "share-cache [<dir>]",
+	Short: "Share one module cache between all Go versions",
+	Long: `By default every version has its own GOPATH, and with it its own module cache,
+so the same modules are downloaded and stored once per version.
+
+share-cache points GOMODCACHE of all versions at <dir> (default ~/.goenv/modcache),
+moves the modules of the existing per-version caches there and regenerates the
+wrappers. With --gocache the build cache is shared as well, in ~/.goenv/gocache.
+
+To go back to per-version caches, remove the cache section from
+~/.goenv/config.json and run goenv fix.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runShareCache,
+}
+
+func init() {
+	shareCacheCmd.Flags().Bool("gocache", false, "Share the build cache as well")
+}
+
+func runShareCache(cmd *cobra.Command, args []string) error {
+	modCache, err := config.GetSharedModCacheDir()
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		if modCache, err = filepath.Abs(args[0]); err != nil {
+			return err
+		}
+	}
+
+	var goCache string
+	if shared, _ := cmd.Flags().GetBool("gocache"); shared {
+		if goCache, err = config.GetSharedGoCacheDir(); err != nil {
+			return err
+		}
+	}
+
+	if err := installer.ShareCaches(modCache, goCache); err != nil {
+		return err
+	}
+	fmt.Printf("All versions now use the module cache in %s\n", modCache)
+	if goCache != "" {
+		fmt.Printf("All versions now use the build cache in %s\n", goCache)
+	}
+	return nil
+}
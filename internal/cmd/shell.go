@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+	"github.com/hitzhangjie/goenv/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+var shellCmd = &cobra.Command{
+	Use:   "shell <version>",
+	Short: "Start a subshell in which go resolves to a Go version",
+	Long: `Start $SHELL with go and gofmt resolving to the given version and the same
+environment as its go<version> wrapper. GOENV_VERSION is set so prompts can show
+the active version. Exit the shell to return to the previous environment;
+no rc files are modified.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeInstalled,
+	SilenceUsage:      true,
+	SilenceErrors:     true,
+	RunE:              runShell,
+}
+
+func runShell(cmd *cobra.Command, args []string) error {
+	inst, err := installer.FindInstalled(args[0])
+	if err != nil {
+		return err
+	}
+
+	env, cleanup, err := installer.Environ(inst)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	env = append(env, config.VersionEnv+"="+inst.Version)
+
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+
+	if current := os.Getenv(config.VersionEnv); current != "" {
+		fmt.Printf("Note: already in a goenv shell for %s, nesting a new one.\n", current)
+	}
+	fmt.Printf("Starting %s with %s, exit to return.\n", shell, inst.Version)
+
+	code, err := installer.Run(env, shell)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Left the %s shell.\n", inst.Version)
+	if code != 0 {
+		return &exitError{code: code}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hitzhangjie/goenv/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+var toolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "Manage the tools installed into each Go version",
+	Long: `Tools such as gopls, dlv or staticcheck are installed into the GOBIN of each
+version, ~/.goenv/<version>/bin. The tools section of ~/.goenv/config.json lists
+the tools every version should have, e.g.
+
+  {
+    "tools": [
+      {"path": "golang.org/x/tools/gopls", "go": {"go1.19": "v0.14.2"}},
+      {"path": "github.com/go-delve/delve/cmd/dlv", "version": "v1.23.0"},
+      {"path": "honnef.co/go/tools/cmd/staticcheck", "go": {"go1.18": "none"}}
+    ],
+    "auto_sync_tools": true
+  }
+
+version defaults to latest. go maps minor lines or versions to another module
+version, or to none to leave the tool out. With auto_sync_tools, goenv install
+syncs the tools into every new version.`,
+}
+
+var toolsSyncCmd = &cobra.Command{
+	Use:   "sync [<version>...]",
+	Short: "Install the configured tools missing from Go versions",
+	Long: `Install the configured tools into the given versions, or into all installed
+versions, using each version's own go command and environment. Tools already
+built from the wanted module version are left alone, failures are reported per
+tool and version. Shims like gopls@go1.22.5 are refreshed afterwards.`,
+	ValidArgsFunction: completeInstalled,
+	SilenceUsage:      true,
+	RunE:              runToolsSync,
+}
+
+func init() {
+	toolsCmd.AddCommand(toolsSyncCmd)
+}
+
+func runToolsSync(cmd *cobra.Command, args []string) error {
+	var insts []installer.Installation
+	if len(args) == 0 {
+		installed, err := installer.ListInstalled()
+		if err != nil {
+			return err
+		}
+		if len(installed) == 0 {
+			return fmt.Errorf("no Go versions installed")
+		}
+		insts = installed
+	}
+	for _, arg := range args {
+		inst, err := installer.FindInstalled(arg)
+		if err != nil {
+			return err
+		}
+		insts = append(insts, *inst)
+	}
+
+	results, err := installer.SyncTools(insts)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	for _, r := range results {
+		counts[r.Status]++
+	}
+	fmt.Printf("\n%d installed, %d up to date, %d skipped, %d failed\n",
+		counts[installer.ToolInstalled], counts[installer.ToolUpToDate], counts[installer.ToolSkipped], counts[installer.ToolFailed])
+
+	if counts[installer.ToolFailed] == 0 {
+		return nil
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Printf("\n%s %s: %v\n", r.Version, r.Module, r.Err)
+		}
+	}
+	return fmt.Errorf("%d tool installs failed", counts[installer.ToolFailed])
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hitzhangjie/goenv/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+var useCmd = &cobra.Command{
+	Use:   "use <version> | --none",
+	Short: "Make plain go and gofmt run a Go version",
+	Long: `Opt in to plain go and gofmt commands in ~/.goenv/bin that run the given
+version with the same environment as its go<version> wrapper.
+
+goenv use --none removes them again. Without arguments the current default
+is shown.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeInstalled,
+	RunE:              runUse,
+}
+
+func init() {
+	useCmd.Flags().Bool("none", false, "Remove the plain go and gofmt commands")
+}
+
+func runUse(cmd *cobra.Command, args []string) error {
+	if none, _ := cmd.Flags().GetBool("none"); none {
+		if len(args) > 0 {
+			return fmt.Errorf("--none takes no version")
+		}
+		if err := installer.ClearDefault(); err != nil {
+			return err
+		}
+		fmt.Println("Removed go and gofmt, no default version is set.")
+		return nil
+	}
+
+	if len(args) == 0 {
+		v, err := installer.GetDefault()
+		if err != nil {
+			return err
+		}
+		if v == "" {
+			fmt.Println("No default version is set.")
+		} else {
+			fmt.Println(v)
+		}
+		return nil
+	}
+
+	inst, err := installer.FindInstalled(args[0])
+	if err != nil {
+		return err
+	}
+	if err := installer.UseDefault(inst); err != nil {
+		return err
+	}
+	fmt.Printf("go and gofmt now run %s\n", inst.Version)
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/hitzhangjie/goenv/internal/cache"
+	"github.com/hitzhangjie/goenv/internal/config"
 	"github.com/hitzhangjie/goenv/internal/github"
 	"github.com/hitzhangjie/goenv/internal/version"
 	"github.com/spf13/cobra"
@@ -25,6 +26,7 @@ func init() {
 	versionsCmd.Flags().String("min-version", "", "Minimum version to fetch (e.g., go1.22)")
 	versionsCmd.Flags().Int("min-year", 0, "Minimum year to fetch versions from (e.g., 2020)")
 	versionsCmd.Flags().Bool("all", false, "Fetch all versions (ignore filters)")
+	versionsCmd.RegisterFlagCompletionFunc("min-version", completeMinorLines)
 }
 
 func runVersions(cmd *cobra.Command, args []string) error {
@@ -36,10 +38,20 @@ func runVersions(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load cached versions: %w", err)
 	}
 
-	// Check if we should update
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return err
+	}
+
+	// Check if we should update, with a TTL configured only once the cache expired
 	shouldUpdate := update || cachedData == nil
 	if !shouldUpdate {
-		shouldUpdate = askForUpdate()
+		if settings.VersionsTTL != "" {
+			ttl, _ := config.ParseAge(settings.VersionsTTL)
+			shouldUpdate = time.Since(cachedData.FetchedAt) > ttl
+		} else {
+			shouldUpdate = askForUpdate()
+		}
 	}
 
 	var versionsData *version.VersionsData
@@ -11,20 +11,86 @@ const (
 	DownloadsDir = "downloads"
 	SDKDir       = "sdk"
 	BinDir       = "bin"
+	ReceiptsDir  = "receipts"
+	BuildDir     = "build"
+	SumDBDir     = "sumdb"
+	DefaultFile  = "version"
+	ShimsDir     = "shims"
+	ConfigFile   = "config.json"
+	ModCacheDir  = "modcache"
+	GoCacheDir   = "gocache"
 )
 
-// GetGoenvRoot returns the root directory for goenv (~/.goenv)
+// VersionEnv names the variable selecting a Go version for the current shell, set by goenv shell
+const VersionEnv = "GOENV_VERSION"
+
+// RootEnv names the variable overriding the goenv root directory
+const RootEnv = "GOENV_ROOT"
+
+// SystemRootEnv names the variable pointing at a shared store of SDKs, set it empty to ignore the store
+const SystemRootEnv = "GOENV_SYSTEM_ROOT"
+
+// DefaultSystemRoot is the shared SDK store used if it exists and GOENV_SYSTEM_ROOT is unset
+const DefaultSystemRoot = "/opt/goenv"
+
+// GetGoenvRoot returns the root directory for goenv data: $GOENV_ROOT or ~/.goenv,
+// or the goenv directory in $XDG_DATA_HOME in the xdg layout
 func GetGoenvRoot() (string, error) {
-	homeDir, err := os.UserHomeDir()
+	layout, err := GetLayout()
+	if err != nil {
+		return "", err
+	}
+	return layout.DataDir, nil
+}
+
+// GetCacheRoot returns the directory for goenv caches, the same as GetGoenvRoot
+// except in the xdg layout
+func GetCacheRoot() (string, error) {
+	layout, err := GetLayout()
+	if err != nil {
+		return "", err
+	}
+	return layout.CacheDir, nil
+}
+
+// GetConfigDir returns the directory holding config.json, the same as GetGoenvRoot
+// except in the xdg layout
+func GetConfigDir() (string, error) {
+	layout, err := GetLayout()
+	if err != nil {
+		return "", err
+	}
+	return layout.ConfigDir, nil
+}
+
+// GetSystemRoot returns the shared store of SDKs installed for all users of the
+// machine: $GOENV_SYSTEM_ROOT, or /opt/goenv if it exists. It returns "" if there
+// is none, or if goenv is managing the store itself as its root.
+func GetSystemRoot() (string, error) {
+	root, ok := os.LookupEnv(SystemRootEnv)
+	if !ok {
+		if _, err := os.Stat(DefaultSystemRoot); err != nil {
+			return "", nil
+		}
+		root = DefaultSystemRoot
+	}
+	if root == "" {
+		return "", nil
+	}
+
+	userRoot, err := GetGoenvRoot()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(homeDir, GoenvDir), nil
+	if filepath.Clean(root) == filepath.Clean(userRoot) {
+		return "", nil
+	}
+	return root, nil
 }
 
 // GetVersionsFile returns the path to versions.json
 func GetVersionsFile() (string, error) {
-	root, err := GetGoenvRoot()
+	root, err := GetCacheRoot()
 	if err != nil {
 		return "", err
 	}
@@ -33,7 +99,7 @@ func GetVersionsFile() (string, error) {
 
 // GetDownloadsDir returns the downloads directory path
 func GetDownloadsDir() (string, error) {
-	root, err := GetGoenvRoot()
+	root, err := GetCacheRoot()
 	if err != nil {
 		return "", err
 	}
@@ -58,6 +124,78 @@ func GetBinDir() (string, error) {
 	return filepath.Join(root, BinDir), nil
 }
 
+// GetReceiptsDir returns the directory holding install receipts
+func GetReceiptsDir() (string, error) {
+	root, err := GetGoenvRoot()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, ReceiptsDir), nil
+}
+
+// GetBuildDir returns the directory used for source builds
+func GetBuildDir() (string, error) {
+	root, err := GetCacheRoot()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, BuildDir), nil
+}
+
+// GetSumDBDir returns the directory holding checksum database state
+func GetSumDBDir() (string, error) {
+	root, err := GetCacheRoot()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, SumDBDir), nil
+}
+
+// GetConfigFile returns the path to config.json
+func GetConfigFile() (string, error) {
+	root, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, ConfigFile), nil
+}
+
+// GetShimsDir returns the directory holding per-version go and gofmt shims
+func GetShimsDir() (string, error) {
+	root, err := GetGoenvRoot()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, ShimsDir), nil
+}
+
+// GetDefaultFile returns the path of the file recording the version chosen with goenv use
+func GetDefaultFile() (string, error) {
+	root, err := GetGoenvRoot()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, DefaultFile), nil
+}
+
+// GetSharedModCacheDir returns the default GOMODCACHE shared by all versions
+func GetSharedModCacheDir() (string, error) {
+	root, err := GetCacheRoot()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, ModCacheDir), nil
+}
+
+// GetSharedGoCacheDir returns the default GOCACHE shared by all versions
+func GetSharedGoCacheDir() (string, error) {
+	root, err := GetCacheRoot()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, GoCacheDir), nil
+}
+
 // EnsureDir ensures that a directory exists, creating it if necessary
 func EnsureDir(path string) error {
 	return os.MkdirAll(path, 0755)
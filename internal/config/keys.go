@@ -0,0 +1,242 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// KeyValue is a setting as shown by goenv config list
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+var versionElemRegex = regexp.MustCompile(`^go\d`)
+
+// splitKey splits a dotted key into path elements, keeping Go versions such as
+// go1.22.5 in env.versions.go1.22.5.GOPROXY in one piece
+func splitKey(key string) []string {
+	var path []string
+	for _, elem := range strings.Split(key, ".") {
+		n := len(path)
+		if n > 0 && elem != "" && elem[0] >= '0' && elem[0] <= '9' && versionElemRegex.MatchString(path[n-1]) {
+			path[n-1] += "." + elem
+			continue
+		}
+		path = append(path, elem)
+	}
+	return path
+}
+
+// jsonName returns the config file name of a struct field
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return name
+}
+
+// lookupType returns the type of the setting at key, or an error for unknown settings
+func lookupType(key string) (reflect.Type, error) {
+	t := reflect.TypeOf(Settings{})
+	path := splitKey(key)
+	for i, elem := range path {
+		switch t.Kind() {
+		case reflect.Struct:
+			found := false
+			for j := 0; j < t.NumField(); j++ {
+				if jsonName(t.Field(j)) == elem {
+					t, found = t.Field(j).Type, true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("unknown setting %q, run 'goenv config list' to see all settings", key)
+			}
+		case reflect.Map:
+			if elem == "" {
+				return nil, fmt.Errorf("invalid setting %q", key)
+			}
+			t = t.Elem()
+		default:
+			return nil, fmt.Errorf("%s has no setting %q, set it as a whole", strings.Join(path[:i], "."), elem)
+		}
+	}
+	return t, nil
+}
+
+// toMap converts settings to their config file form
+func toMap(s *Settings) (map[string]any, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]any)
+	return m, json.Unmarshal(data, &m)
+}
+
+// fromMap converts the config file form back to validated settings
+func fromMap(m map[string]any) (*Settings, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSettings(data)
+}
+
+// GetKey returns the value of a setting, e.g. "mirror" or "env.lines.go1.19.GOPROXY".
+// Strings are returned as they are, other values and whole sections as JSON.
+func GetKey(s *Settings, key string) (string, error) {
+	t, err := lookupType(key)
+	if err != nil {
+		return "", err
+	}
+	m, err := toMap(s)
+	if err != nil {
+		return "", err
+	}
+
+	var value any = m
+	for _, elem := range splitKey(key) {
+		section, _ := value.(map[string]any)
+		if value = section[elem]; value == nil {
+			value = reflect.Zero(t).Interface()
+			break
+		}
+	}
+	return formatValue(value, "  ")
+}
+
+// SetKey returns a copy of s with a setting changed. The value is taken as it is
+// for string settings and parsed as JSON for all others, e.g. numbers or the tools list.
+func SetKey(s *Settings, key, value string) (*Settings, error) {
+	t, err := lookupType(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var v any = value
+	if t.Kind() != reflect.String {
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return nil, fmt.Errorf("%s takes %s, got %q", key, typeName(t), value)
+		}
+	}
+
+	m, err := toMap(s)
+	if err != nil {
+		return nil, err
+	}
+	path := splitKey(key)
+	section := m
+	for _, elem := range path[:len(path)-1] {
+		next, ok := section[elem].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			section[elem] = next
+		}
+		section = next
+	}
+	section[path[len(path)-1]] = v
+	return fromMap(m)
+}
+
+// UnsetKey returns a copy of s with a setting removed, so its default applies again
+func UnsetKey(s *Settings, key string) (*Settings, error) {
+	if _, err := lookupType(key); err != nil {
+		return nil, err
+	}
+	m, err := toMap(s)
+	if err != nil {
+		return nil, err
+	}
+	unset(m, splitKey(key))
+	return fromMap(m)
+}
+
+// unset removes path from m, along with sections left empty
+func unset(m map[string]any, path []string) {
+	if len(path) == 1 {
+		delete(m, path[0])
+		return
+	}
+	if section, ok := m[path[0]].(map[string]any); ok {
+		unset(section, path[1:])
+		if len(section) == 0 {
+			delete(m, path[0])
+		}
+	}
+}
+
+// ListKeys returns every setting of s, sorted by section and key
+func ListKeys(s *Settings) ([]KeyValue, error) {
+	var list []KeyValue
+	err := flatten("", reflect.ValueOf(*s), &list)
+	return list, err
+}
+
+func flatten(prefix string, v reflect.Value, list *[]KeyValue) error {
+	join := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "." + name
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if err := flatten(join(jsonName(v.Type().Field(i))), v.Field(i), list); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := flatten(join(k), v.MapIndex(reflect.ValueOf(k)), list); err != nil {
+				return err
+			}
+		}
+	default:
+		value, err := formatValue(v.Interface(), "")
+		if err != nil {
+			return err
+		}
+		*list = append(*list, KeyValue{prefix, value})
+	}
+	return nil
+}
+
+// formatValue returns strings as they are and everything else as JSON
+func formatValue(value any, indent string) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Slice && v.IsNil() {
+		return "[]", nil
+	}
+	if indent == "" {
+		data, err := json.Marshal(value)
+		return string(data), err
+	}
+	data, err := json.MarshalIndent(value, "", indent)
+	return string(data), err
+}
+
+// typeName describes the values a setting of type t takes
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int:
+		return "a number"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Slice:
+		return "a JSON list"
+	}
+	return "a JSON object"
+}
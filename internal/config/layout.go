@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Directory layouts
+const (
+	LayoutClassic = "classic" // Everything in ~/.goenv, or in $GOENV_ROOT
+	LayoutXDG     = "xdg"     // Config, caches and data in the XDG base directories
+)
+
+// LayoutEnv names the variable choosing the directory layout
+const LayoutEnv = "GOENV_LAYOUT"
+
+// Layout holds the directories goenv keeps its files in, in the classic layout they are all the same
+type Layout struct {
+	Name      string
+	ConfigDir string // config.json
+	CacheDir  string // Version list, downloads, builds, checksum database state and build caches
+	DataDir   string // SDKs, wrappers, receipts and per-version GOPATHs
+}
+
+// GetLayout returns the layout in use. GOENV_ROOT means the classic layout rooted there,
+// then GOENV_LAYOUT decides. Otherwise an existing ~/.goenv means classic, an existing
+// $XDG_DATA_HOME/goenv means xdg, and new installations start out classic.
+func GetLayout() (*Layout, error) {
+	if root := os.Getenv(RootEnv); root != "" {
+		root, err := filepath.Abs(root)
+		if err != nil {
+			return nil, err
+		}
+		return &Layout{Name: LayoutClassic, ConfigDir: root, CacheDir: root, DataDir: root}, nil
+	}
+	if name := os.Getenv(LayoutEnv); name != "" {
+		return LayoutDirs(name)
+	}
+
+	classic, err := LayoutDirs(LayoutClassic)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(classic.DataDir); err == nil {
+		return classic, nil
+	}
+	xdg, err := LayoutDirs(LayoutXDG)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(xdg.DataDir); err == nil {
+		return xdg, nil
+	}
+	return classic, nil
+}
+
+// LayoutDirs returns the directories of the named layout, ignoring GOENV_ROOT
+func LayoutDirs(name string) (*Layout, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case LayoutClassic:
+		root := filepath.Join(homeDir, GoenvDir)
+		return &Layout{Name: name, ConfigDir: root, CacheDir: root, DataDir: root}, nil
+	case LayoutXDG:
+		return &Layout{
+			Name:      name,
+			ConfigDir: xdgDir(homeDir, "XDG_CONFIG_HOME", ".config"),
+			CacheDir:  xdgDir(homeDir, "XDG_CACHE_HOME", ".cache"),
+			DataDir:   xdgDir(homeDir, "XDG_DATA_HOME", filepath.Join(".local", "share")),
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown layout %q, want %s or %s", name, LayoutClassic, LayoutXDG)
+}
+
+// xdgDir returns the goenv directory in the XDG base directory named by env.
+// As the spec requires, relative values are ignored in favour of the default.
+func xdgDir(homeDir, env, fallback string) string {
+	if dir := os.Getenv(env); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, "goenv")
+	}
+	return filepath.Join(homeDir, fallback, "goenv")
+}
@@ -0,0 +1,354 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Wrapper modes
+const (
+	WrapperScript   = "script"   // bash scripts, the original wrappers and the default
+	WrapperShim     = "shim"     // Symlinks to the goenv binary, which dispatches on its name
+	WrapperPortable = "portable" // bash scripts finding the goenv root relative to their own location
+)
+
+// Defaults of the settings
+const (
+	DefaultMirror      = "https://dl.google.com/go/"
+	DefaultSource      = "download"
+	DefaultConcurrency = 1
+)
+
+// DefaultToolShimPattern names the shims of tools installed into the GOBIN of a version
+const DefaultToolShimPattern = "{tool}@{version}"
+
+// Settings is the user configuration stored in config.json
+type Settings struct {
+	Mirror          string        `json:"mirror,omitempty"`       // Base URL of SDK and source archives
+	Source          string        `json:"source,omitempty"`       // Default goenv install --source, download or proxy
+	Proxy           string        `json:"proxy,omitempty"`        // Module proxy for --source proxy, GOPROXY if empty
+	VersionsTTL     string        `json:"versions_ttl,omitempty"` // How long goenv versions uses its cache without asking, e.g. "24h"
+	Concurrency     int           `json:"concurrency,omitempty"`  // Parallel jobs, e.g. tool installs
+	WrapperMode     string        `json:"wrapper_mode,omitempty"`
+	ToolShimPattern string        `json:"tool_shim_pattern,omitempty"`
+	Env             EnvSettings   `json:"env,omitzero"`
+	Cache           CacheSettings `json:"cache,omitzero"`
+	Tools           []Tool        `json:"tools,omitempty"`
+	AutoSyncTools   bool          `json:"auto_sync_tools,omitempty"`
+	AutoDedupe      bool          `json:"auto_dedupe,omitempty"` // Run goenv dedupe after every install
+}
+
+// ToolSkip as the version of a tool leaves it out for a Go version
+const ToolSkip = "none"
+
+// Tool is a tool goenv tools sync installs into the GOBIN of every version
+type Tool struct {
+	Path    string            `json:"path"`              // Package to install, e.g. "golang.org/x/tools/gopls"
+	Version string            `json:"version,omitempty"` // Module version, "latest" if empty
+	Go      map[string]string `json:"go,omitempty"`      // Versions for minor lines or Go versions, e.g. {"go1.19": "v0.14.2"}
+}
+
+// VersionFor returns the module version of the tool for a Go version of line,
+// or ToolSkip if it is not wanted there
+func (t *Tool) VersionFor(version, line string) string {
+	if v, ok := t.Go[version]; ok {
+		return v
+	}
+	if v, ok := t.Go[line]; ok {
+		return v
+	}
+	if t.Version == "" {
+		return "latest"
+	}
+	return t.Version
+}
+
+// CacheSettings choose between per-version and shared caches.
+// By default every version has its own module and build cache under ~/.goenv/<version>.
+type CacheSettings struct {
+	ModCache string `json:"modcache,omitempty"` // Shared GOMODCACHE, "" for per-version caches
+	GoCache  string `json:"gocache,omitempty"`  // Shared GOCACHE, "" for per-version caches
+}
+
+// EnvSettings are extra environment variables set by the wrappers and goenv exec,
+// e.g. GOFLAGS, GOPRIVATE, GOPROXY, CGO_ENABLED or GODEBUG.
+// Per-version values override per-line values, which override global ones.
+type EnvSettings struct {
+	Global   map[string]string            `json:"global,omitempty"`
+	Lines    map[string]map[string]string `json:"lines,omitempty"`    // Keyed by minor line, e.g. "go1.19"
+	Versions map[string]map[string]string `json:"versions,omitempty"` // Keyed by version, e.g. "go1.19.13"
+}
+
+// DefaultToolchain is the GOTOOLCHAIN policy of the wrappers unless env configures another:
+// always run the wrapper's own SDK, never switch to or download a different toolchain
+const DefaultToolchain = "local"
+
+// ManagedEnv are the variables goenv sets itself, they cannot be overridden
+var ManagedEnv = []string{"GOROOT", "GOPATH", "GOBIN", "GOCACHE", "GOTESTCACHE", "GOENV", "GOMODCACHE"}
+
+var (
+	envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	lineRegex    = regexp.MustCompile(`^go\d+\.\d+$`)
+	// A character that cannot occur in version names, including variants and development builds
+	separatorRegex = regexp.MustCompile(`[^A-Za-z0-9._+-]`)
+	// local, auto, path, or a toolchain name, optionally followed by +auto or +path
+	toolchainRegex = regexp.MustCompile(`^(local|auto|path|go[^+\s]+)(\+(auto|path))?$`)
+)
+
+// Lookup returns the variables configured for version, which belongs to line
+func (e *EnvSettings) Lookup(version, line string) map[string]string {
+	env := make(map[string]string)
+	for _, scope := range []map[string]string{e.Global, e.Lines[line], e.Versions[version]} {
+		for name, value := range scope {
+			env[name] = value
+		}
+	}
+	return env
+}
+
+// validate checks the variable names of every scope
+func (e *EnvSettings) validate() error {
+	check := func(scope string, vars map[string]string) error {
+		names := make([]string, 0, len(vars))
+		for name := range vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if !envNameRegex.MatchString(name) {
+				return fmt.Errorf("%s: invalid environment variable name %q", scope, name)
+			}
+			for _, managed := range ManagedEnv {
+				if name == managed {
+					return fmt.Errorf("%s: %s is set by goenv and cannot be overridden", scope, name)
+				}
+			}
+			if name == "GOTOOLCHAIN" && !toolchainRegex.MatchString(vars[name]) {
+				return fmt.Errorf("%s: invalid GOTOOLCHAIN %q, want local, auto, path or a toolchain name like go1.22.5+auto", scope, vars[name])
+			}
+		}
+		return nil
+	}
+
+	if err := check("env.global", e.Global); err != nil {
+		return err
+	}
+	for line, vars := range e.Lines {
+		if !lineRegex.MatchString(line) {
+			return fmt.Errorf("env.lines: %q is not a minor line like go1.22", line)
+		}
+		if err := check("env.lines."+line, vars); err != nil {
+			return err
+		}
+	}
+	for version, vars := range e.Versions {
+		if err := check("env.versions."+version, vars); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadSettings loads config.json, missing files and fields get their defaults
+func LoadSettings() (*Settings, error) {
+	path, err := GetConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		data = []byte("{}")
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	settings, err := ParseSettings(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// ParseSettings parses and validates the contents of a config file.
+// Unknown fields are rejected so typos do not go unnoticed, missing ones get their defaults.
+func ParseSettings(data []byte) (*Settings, error) {
+	settings := &Settings{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(settings); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("%s must be of type %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+		}
+		return nil, err
+	}
+
+	settings.setDefaults(false)
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// ParseToolShimPattern checks a tool_shim_pattern and turns it into a regexp
+// capturing the tool and version of the shim names following it
+func ParseToolShimPattern(pattern string) (*regexp.Regexp, error) {
+	if !strings.Contains(pattern, "{tool}") || !strings.Contains(pattern, "{version}") {
+		return nil, fmt.Errorf("tool_shim_pattern must contain {tool} and {version}, got %q", pattern)
+	}
+	if strings.ContainsRune(pattern, '/') {
+		return nil, fmt.Errorf("tool_shim_pattern must be a file name, got %q", pattern)
+	}
+	// Without a character that cannot occur in versions, the pattern could match
+	// the go and gofmt wrappers, e.g. {version}.{tool} matches go1.22.5
+	literal := strings.NewReplacer("{tool}", "", "{version}", "").Replace(pattern)
+	if !HasSeparator(literal) {
+		return nil, fmt.Errorf("tool_shim_pattern must contain a separator like @ that cannot appear in Go versions, got %q", pattern)
+	}
+
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.Replace(expr, regexp.QuoteMeta("{tool}"), `(?P<tool>.+?)`, 1)
+	expr = strings.Replace(expr, regexp.QuoteMeta("{version}"), `(?P<version>go[^/]+?)`, 1)
+	return regexp.MustCompile("^" + expr + "$"), nil
+}
+
+// HasSeparator reports whether name contains a character that cannot occur in
+// version names, as every name following a valid tool_shim_pattern does
+func HasSeparator(name string) bool {
+	return separatorRegex.MatchString(name)
+}
+
+// setDefaults fills in the defaults of unset settings, or clears settings equal to their default
+func (s *Settings) setDefaults(clear bool) {
+	defaults := []struct {
+		value *string
+		def   string
+	}{
+		{&s.Mirror, DefaultMirror},
+		{&s.Source, DefaultSource},
+		{&s.WrapperMode, WrapperScript},
+		{&s.ToolShimPattern, DefaultToolShimPattern},
+	}
+	for _, d := range defaults {
+		switch {
+		case clear && *d.value == d.def:
+			*d.value = ""
+		case !clear && *d.value == "":
+			*d.value = d.def
+		}
+	}
+
+	switch {
+	case clear && s.Concurrency == DefaultConcurrency:
+		s.Concurrency = 0
+	case !clear && s.Concurrency == 0:
+		s.Concurrency = DefaultConcurrency
+	}
+}
+
+// SaveSettings writes config.json
+func SaveSettings(settings *Settings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	path, err := GetConfigFile()
+	if err != nil {
+		return err
+	}
+	if err := EnsureDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to create goenv directory: %w", err)
+	}
+
+	// Defaults are left out so the file only shows what the user changed
+	saved := *settings
+	saved.setDefaults(true)
+	data, err := json.MarshalIndent(&saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// Validate checks the settings for invalid values
+func (s *Settings) Validate() error {
+	if s.Mirror != "" {
+		if u, err := url.Parse(s.Mirror); err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+			return fmt.Errorf("mirror must be an http(s) URL like %s, got %q", DefaultMirror, s.Mirror)
+		}
+	}
+	switch s.Source {
+	case "", "download", "proxy":
+	default:
+		return fmt.Errorf("source must be \"download\" or \"proxy\", got %q", s.Source)
+	}
+	if s.Proxy != "" {
+		if u, err := url.Parse(s.Proxy); err != nil || u.Scheme == "" {
+			return fmt.Errorf("proxy must be a URL like https://proxy.golang.org, got %q", s.Proxy)
+		}
+	}
+	if s.VersionsTTL != "" {
+		if _, err := ParseAge(s.VersionsTTL); err != nil {
+			return fmt.Errorf("versions_ttl: %w", err)
+		}
+	}
+	if s.Concurrency < 0 {
+		return fmt.Errorf("concurrency must be at least 1, got %d", s.Concurrency)
+	}
+	switch s.WrapperMode {
+	case "", WrapperShim, WrapperScript, WrapperPortable:
+	default:
+		return fmt.Errorf("wrapper_mode must be %q, %q or %q, got %q", WrapperShim, WrapperScript, WrapperPortable, s.WrapperMode)
+	}
+	if s.ToolShimPattern != "" {
+		if _, err := ParseToolShimPattern(s.ToolShimPattern); err != nil {
+			return err
+		}
+	}
+	if s.Cache.ModCache != "" && !filepath.IsAbs(s.Cache.ModCache) {
+		return fmt.Errorf("cache.modcache must be an absolute path, got %q", s.Cache.ModCache)
+	}
+	if s.Cache.GoCache != "" && !filepath.IsAbs(s.Cache.GoCache) {
+		return fmt.Errorf("cache.gocache must be an absolute path, got %q", s.Cache.GoCache)
+	}
+	for i, tool := range s.Tools {
+		if tool.Path == "" {
+			return fmt.Errorf("tools[%d]: path is missing", i)
+		}
+		for key, v := range tool.Go {
+			if !strings.HasPrefix(key, "go") || v == "" {
+				return fmt.Errorf("tools[%d] %s: want a Go version or line mapped to a module version or %q, got %q: %q", i, tool.Path, ToolSkip, key, v)
+			}
+		}
+	}
+	return s.Env.validate()
+}
+
+// ParseAge parses a duration like time.ParseDuration, also accepting whole days such as "30d"
+func ParseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q, want e.g. 30d or 12h", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q, want e.g. 30d or 12h", s)
+	}
+	return d, nil
+}
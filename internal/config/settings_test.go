@@ -0,0 +1,131 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateToolShimPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		ok      bool
+	}{
+		{"{tool}@{version}", true},
+		{"{version}@{tool}", true},
+		{"{tool}~{version}", true},
+		{"{tool}@@{version}", true},
+		{"{tool}", false},
+		{"{tool}/{version}", false},
+		// These match wrappers such as go1.22.5, godev-f52d441ca8 or gofmt-godev-f52d441ca8
+		{"{version}.{tool}", false},
+		{"{version}-{tool}", false},
+		{"{tool}{version}", false},
+		{"{tool}_{version}", false},
+		{"{tool}_at_{version}", false},
+		{"{tool}+{version}", false},
+	}
+	for _, tt := range tests {
+		s := &Settings{ToolShimPattern: tt.pattern}
+		if err := s.Validate(); (err == nil) != tt.ok {
+			t.Errorf("Validate with tool_shim_pattern %q: got error %v, want ok = %v", tt.pattern, err, tt.ok)
+		}
+	}
+}
+
+func TestEnvLookup(t *testing.T) {
+	env := EnvSettings{
+		Global: map[string]string{"GOPRIVATE": "git.example.com", "GOFLAGS": "-mod=mod", "CGO_ENABLED": "1"},
+		Lines: map[string]map[string]string{
+			"go1.19": {"GOPROXY": "https://legacy.example.com", "GOFLAGS": "-mod=vendor"},
+			"go1.22": {"GOPROXY": "https://proxy.example.com"},
+		},
+		Versions: map[string]map[string]string{
+			"go1.19.13": {"CGO_ENABLED": "0", "GOFLAGS": ""},
+		},
+	}
+	tests := []struct {
+		version, line string
+		want          map[string]string
+	}{
+		// The version overrides its line, which overrides the global scope
+		{"go1.19.13", "go1.19", map[string]string{
+			"GOPRIVATE": "git.example.com", "GOFLAGS": "", "CGO_ENABLED": "0", "GOPROXY": "https://legacy.example.com",
+		}},
+		{"go1.19.12", "go1.19", map[string]string{
+			"GOPRIVATE": "git.example.com", "GOFLAGS": "-mod=vendor", "CGO_ENABLED": "1", "GOPROXY": "https://legacy.example.com",
+		}},
+		{"go1.23.0", "go1.23", map[string]string{
+			"GOPRIVATE": "git.example.com", "GOFLAGS": "-mod=mod", "CGO_ENABLED": "1",
+		}},
+		// Development builds belong to no line
+		{"godev-f52d441ca8", "", map[string]string{
+			"GOPRIVATE": "git.example.com", "GOFLAGS": "-mod=mod", "CGO_ENABLED": "1",
+		}},
+	}
+	for _, tt := range tests {
+		got := env.Lookup(tt.version, tt.line)
+		if len(got) != len(tt.want) {
+			t.Errorf("Lookup(%s, %s) = %v, want %v", tt.version, tt.line, got, tt.want)
+			continue
+		}
+		for name, value := range tt.want {
+			if v, ok := got[name]; !ok || v != value {
+				t.Errorf("Lookup(%s, %s) = %v, want %v", tt.version, tt.line, got, tt.want)
+				break
+			}
+		}
+	}
+
+	// Lookup must not write into the configured scopes
+	if env.Global["GOFLAGS"] != "-mod=mod" || len(env.Global) != 3 {
+		t.Errorf("Lookup modified the global scope: %v", env.Global)
+	}
+}
+
+func TestValidateEnv(t *testing.T) {
+	tests := []struct {
+		env EnvSettings
+		ok  bool
+	}{
+		{EnvSettings{Global: map[string]string{"GOPRIVATE": "x", "_X1": "y"}}, true},
+		{EnvSettings{Global: map[string]string{"1X": "y"}}, false},
+		{EnvSettings{Lines: map[string]map[string]string{"go1.22": {"GOFLAGS=": "x"}}}, false},
+		// Variables goenv sets itself cannot be overridden in any scope
+		{EnvSettings{Global: map[string]string{"GOROOT": "/usr/local/go"}}, false},
+		{EnvSettings{Versions: map[string]map[string]string{"go1.22.5": {"GOMODCACHE": "/tmp/mod"}}}, false},
+		{EnvSettings{Lines: map[string]map[string]string{"1.22": {"GOFLAGS": "x"}}}, false},
+		{EnvSettings{Lines: map[string]map[string]string{"go1.22.5": {"GOFLAGS": "x"}}}, false},
+		{EnvSettings{Global: map[string]string{"GOTOOLCHAIN": "go1.22.5+auto"}}, true},
+		{EnvSettings{Global: map[string]string{"GOTOOLCHAIN": "newest"}}, false},
+	}
+	for _, tt := range tests {
+		s := &Settings{Env: tt.env}
+		if err := s.Validate(); (err == nil) != tt.ok {
+			t.Errorf("Validate with env %+v: got error %v, want ok = %v", tt.env, err, tt.ok)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Duration
+		ok   bool
+	}{
+		{"30d", 30 * 24 * time.Hour, true},
+		{"0d", 0, true},
+		{"12h", 12 * time.Hour, true},
+		{"90m", 90 * time.Minute, true},
+		{"-1d", 0, false},
+		{"-2h", 0, false},
+		{"1.5d", 0, false},
+		{"d", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseAge(tt.s)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseAge(%q) = %v, %v, want %v, ok = %v", tt.s, got, err, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package installer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+	"github.com/hitzhangjie/goenv/internal/system"
+)
+
+// Ways of bringing an existing SDK into goenv
+const (
+	AdoptLink     = "link"     // Symlink the SDK into sdk/, the original must stay in place
+	AdoptHardlink = "hardlink" // Hardlink every file, copying those that cannot be linked
+	AdoptCopy     = "copy"     // Copy every file
+	AdoptMove     = "move"     // Move the SDK into sdk/
+)
+
+// Candidate is a Go SDK found outside goenv
+type Candidate struct {
+	Version string
+	Dir     string
+}
+
+// DiscoverSDKs looks for Go SDKs in the usual places: ~/sdk/go* from golang.org/dl,
+// /usr/local/go and toolchains the go command downloaded into GOMODCACHE.
+func DiscoverSDKs() ([]Candidate, error) {
+	var patterns []string
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		patterns = append(patterns, filepath.Join(homeDir, "sdk", "go*"))
+	}
+	patterns = append(patterns, "/usr/local/go")
+	if modcache, err := system.GetModCache(); err == nil {
+		patterns = append(patterns, filepath.Join(modcache, "golang.org", "toolchain@v*"))
+	}
+
+	var dirs []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, matches...)
+	}
+	return inspectSDKs(dirs), nil
+}
+
+// inspectSDKs returns the directories that hold a Go SDK, skipping duplicates
+// and SDKs that already live inside goenv or its system store
+func inspectSDKs(dirs []string) []Candidate {
+	sdkDir, _ := config.GetSDKDir()
+	systemSDKDir := ""
+	if systemRoot, _ := config.GetSystemRoot(); systemRoot != "" {
+		systemSDKDir = filepath.Join(systemRoot, config.SDKDir)
+	}
+
+	seen := make(map[string]bool)
+	var candidates []Candidate
+	for _, dir := range dirs {
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err != nil || seen[resolved] {
+			continue
+		}
+		seen[resolved] = true
+		if sdkDir != "" && strings.HasPrefix(resolved, sdkDir+string(filepath.Separator)) {
+			continue
+		}
+		if systemSDKDir != "" && strings.HasPrefix(resolved, systemSDKDir+string(filepath.Separator)) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(resolved, "bin", "go")); err != nil {
+			continue
+		}
+		v, err := ReadGoRootVersion(resolved)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, Candidate{Version: v, Dir: resolved})
+	}
+	return candidates
+}
+
+// ReadGoRootVersion reads the Go version from the VERSION file of a GOROOT
+func ReadGoRootVersion(goroot string) (string, error) {
+	f, err := os.Open(filepath.Join(goroot, "VERSION"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		return "", fmt.Errorf("empty VERSION file in %s", goroot)
+	}
+	v := strings.TrimSpace(scanner.Text())
+	if !strings.HasPrefix(v, "go") || strings.ContainsAny(v, " \t") {
+		return "", fmt.Errorf("unsupported version %q in %s", v, goroot)
+	}
+	return v, nil
+}
+
+// Adopt brings the Go SDK at goroot into goenv using mode and generates its wrappers.
+// It returns the adopted version.
+func Adopt(goroot, mode string) (string, error) {
+	goroot, err := filepath.Abs(goroot)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(filepath.Join(goroot, "bin", "go")); err != nil {
+		return "", fmt.Errorf("%s is not a Go SDK: %w", goroot, err)
+	}
+	v, err := ReadGoRootVersion(goroot)
+	if err != nil {
+		return "", err
+	}
+	if err := importSDK(goroot, v, mode, SourceAdopt); err != nil {
+		return "", err
+	}
+	return v, nil
+}
+
+// importSDK places the SDK at goroot into sdk/<version> and records where it came from
+func importSDK(goroot, version, mode, source string) error {
+	sdkDir, err := config.GetSDKDir()
+	if err != nil {
+		return err
+	}
+	if err := config.EnsureDir(sdkDir); err != nil {
+		return fmt.Errorf("failed to create SDK directory: %w", err)
+	}
+
+	binDir, err := config.GetBinDir()
+	if err != nil {
+		return err
+	}
+	if err := config.EnsureDir(binDir); err != nil {
+		return fmt.Errorf("failed to create bin directory: %w", err)
+	}
+
+	installDir := filepath.Join(sdkDir, version)
+	if _, err := os.Lstat(installDir); err == nil {
+		return fmt.Errorf("%s is already installed", version)
+	}
+
+	switch mode {
+	case AdoptLink:
+		err = os.Symlink(goroot, installDir)
+	case AdoptHardlink:
+		err = linkTree(goroot, installDir)
+	case AdoptCopy:
+		err = copyTree(goroot, installDir)
+	case AdoptMove:
+		err = moveTree(goroot, installDir)
+	default:
+		return fmt.Errorf("unknown mode %q, want %s, %s, %s or %s", mode, AdoptLink, AdoptHardlink, AdoptCopy, AdoptMove)
+	}
+	if err != nil {
+		if mode != AdoptMove {
+			os.RemoveAll(installDir)
+		}
+		return fmt.Errorf("failed to %s %s: %w", mode, goroot, err)
+	}
+
+	if err := createGoScript(version, installDir, binDir); err != nil {
+		return fmt.Errorf("failed to create go script: %w", err)
+	}
+	if err := createGofmtScript(version, installDir, binDir); err != nil {
+		return fmt.Errorf("failed to create gofmt script: %w", err)
+	}
+
+	receipt := &Receipt{
+		Version:     version,
+		Source:      source,
+		Origin:      goroot,
+		Mode:        mode,
+		InstalledAt: time.Now(),
+	}
+	return SaveReceipt(receipt)
+}
@@ -2,58 +2,229 @@ package installer
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/hitzhangjie/goenv/internal/config"
+	"github.com/hitzhangjie/goenv/internal/version"
 )
 
-// CleanupDownloads removes all files from the downloads directory
-func CleanupDownloads() error {
-	downloadsDir, err := config.GetDownloadsDir()
+// partialTimeout is how long a .part file goes unmodified before it counts as
+// left behind by an interrupted download rather than one in progress
+const partialTimeout = time.Hour
+
+// archiveVersionRegex finds the Go version in an archive name, e.g. go1.22.5.linux-amd64.tar.gz,
+// go1.22.5.src.tar.gz or toolchain-v0.0.1-go1.21.13.linux-amd64.zip
+var archiveVersionRegex = regexp.MustCompile(`go[0-9]+(\.[0-9]+)*((rc|beta)[0-9]+)?\.`)
+
+// CleanupOptions select what goenv cleanup removes
+type CleanupOptions struct {
+	KeepInstalled bool          // Keep the archives of installed versions
+	Keep          int           // Keep the N most recent archives
+	OlderThan     time.Duration // Only remove archives older than this, 0 for all
+	Caches        bool          // Also remove the build caches
+	DryRun        bool          // Only report what would be removed
+}
+
+// cleanupItem is a file or directory goenv cleanup removes
+type cleanupItem struct {
+	path string
+	name string // Shown to the user
+	size int64
+}
+
+// Cleanup removes downloaded archives according to the retention options, partial
+// downloads left behind by interrupted installs and optionally the build caches
+func Cleanup(opts CleanupOptions) error {
+	archives, err := downloadsToClean(opts)
 	if err != nil {
 		return err
 	}
+	items := archives
+	if opts.Caches {
+		caches, err := cachesToClean()
+		if err != nil {
+			return err
+		}
+		items = append(items, caches...)
+	}
+
+	if len(items) == 0 {
+		fmt.Println("Nothing to clean up.")
+		return nil
+	}
+
+	var freed int64
+	removedCount := 0
+	for _, item := range items {
+		if opts.DryRun {
+			fmt.Printf("Would remove: %s (%s)\n", item.name, FormatBytes(item.size))
+			freed += item.size
+			continue
+		}
+		if err := removeTree(item.path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove %s: %v\n", item.path, err)
+			continue
+		}
+		fmt.Printf("Removed: %s\n", item.name)
+		freed += item.size
+		removedCount++
+	}
 
-	// Check if directory exists
+	if opts.DryRun {
+		fmt.Printf("Would free %s by removing %d item(s).\n", FormatBytes(freed), len(items))
+		return nil
+	}
+	fmt.Printf("Freed %s by removing %d item(s).\n", FormatBytes(freed), removedCount)
+	return nil
+}
+
+// downloadsToClean returns the archives in the downloads directory the options do
+// not keep, and the partial downloads in it and its subdirectories
+func downloadsToClean(opts CleanupOptions) ([]cleanupItem, error) {
+	downloadsDir, err := config.GetDownloadsDir()
+	if err != nil {
+		return nil, err
+	}
 	info, err := os.Stat(downloadsDir)
 	if os.IsNotExist(err) {
-		fmt.Println("Downloads directory does not exist, nothing to clean.")
-		return nil
+		return nil, nil
 	}
 	if err != nil {
-		return fmt.Errorf("failed to check downloads directory: %w", err)
+		return nil, fmt.Errorf("failed to check downloads directory: %w", err)
 	}
 	if !info.IsDir() {
-		return fmt.Errorf("downloads path exists but is not a directory: %s", downloadsDir)
+		return nil, fmt.Errorf("downloads path exists but is not a directory: %s", downloadsDir)
 	}
 
-	// Read directory contents
-	entries, err := os.ReadDir(downloadsDir)
-	if err != nil {
-		return fmt.Errorf("failed to read downloads directory: %w", err)
+	installed := make(map[string]bool)
+	if opts.KeepInstalled {
+		all, err := ListInstalled()
+		if err != nil {
+			return nil, err
+		}
+		for _, inst := range all {
+			// Variants are built from the source archive of their base version
+			base, _ := version.SplitVariant(inst.Version)
+			installed[base] = true
+		}
 	}
 
-	if len(entries) == 0 {
-		fmt.Println("Downloads directory is already empty.")
+	var items []cleanupItem
+	var archives []fs.FileInfo
+	now := time.Now()
+	err = filepath.WalkDir(downloadsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(downloadsDir, path)
+
+		if strings.HasSuffix(d.Name(), PartSuffix) {
+			if now.Sub(info.ModTime()) > partialTimeout {
+				items = append(items, cleanupItem{path, rel, info.Size()})
+			}
+			return nil
+		}
+		if filepath.Dir(rel) == "." {
+			archives = append(archives, info)
+		}
 		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read downloads directory: %w", err)
 	}
 
-	// Remove all files
-	removedCount := 0
+	// Newest first, so the first Keep archives are the ones to keep
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].ModTime().After(archives[j].ModTime())
+	})
+	for i, info := range archives {
+		switch {
+		case i < opts.Keep:
+			continue
+		case opts.OlderThan > 0 && now.Sub(info.ModTime()) < opts.OlderThan:
+			continue
+		case installed[archiveVersion(info.Name())]:
+			continue
+		}
+		items = append(items, cleanupItem{filepath.Join(downloadsDir, info.Name()), info.Name(), info.Size()})
+	}
+	return items, nil
+}
+
+// archiveVersion returns the Go version an archive contains, or "" if it is not known
+func archiveVersion(name string) string {
+	return strings.TrimSuffix(archiveVersionRegex.FindString(name), ".")
+}
+
+// cachesToClean returns the per-version build caches and the shared build cache
+func cachesToClean() ([]cleanupItem, error) {
+	cacheRoot, err := config.GetCacheRoot()
+	if err != nil {
+		return nil, err
+	}
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	entries, err := os.ReadDir(cacheRoot)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
 	for _, entry := range entries {
-		if entry.IsDir() {
-			continue // Skip subdirectories
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "go") || cacheEntries[entry.Name()] {
+			continue
 		}
-		filePath := filepath.Join(downloadsDir, entry.Name())
-		if err := os.Remove(filePath); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to remove %s: %v\n", filePath, err)
+		for _, cache := range versionCaches {
+			dirs = append(dirs, filepath.Join(cacheRoot, entry.Name(), cache))
+		}
+	}
+	if settings.Cache.GoCache != "" {
+		dirs = append(dirs, settings.Cache.GoCache)
+	}
+
+	var items []cleanupItem
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); err != nil {
 			continue
 		}
-		removedCount++
-		fmt.Printf("Removed: %s\n", entry.Name())
+		size, err := dirSize(dir)
+		if err != nil {
+			return nil, err
+		}
+		name := dir
+		if rel, err := filepath.Rel(cacheRoot, dir); err == nil && isInside(cacheRoot, dir) {
+			name = rel
+		}
+		items = append(items, cleanupItem{dir, name, size})
 	}
+	return items, nil
+}
 
-	fmt.Printf("Cleaned up %d file(s) from downloads directory.\n", removedCount)
-	return nil
+// dirSize returns the size of the regular files in dir
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
 }
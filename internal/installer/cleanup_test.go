@@ -0,0 +1,101 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+func TestArchiveVersion(t *testing.T) {
+	tests := []struct{ name, want string }{
+		{"go1.22.5.linux-amd64.tar.gz", "go1.22.5"},
+		{"go1.22.5.src.tar.gz", "go1.22.5"},
+		{"go1.23rc1.darwin-arm64.tar.gz", "go1.23rc1"},
+		{"go1.21beta2.windows-amd64.zip", "go1.21beta2"},
+		{"go1.20.linux-amd64.tar.gz", "go1.20"},
+		{"toolchain-v0.0.1-go1.21.13.linux-amd64.zip", "go1.21.13"},
+		{"notes.txt", ""},
+	}
+	for _, tt := range tests {
+		if got := archiveVersion(tt.name); got != tt.want {
+			t.Errorf("archiveVersion(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDownloadsToClean(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv(config.RootEnv, root)
+	t.Setenv(config.SystemRootEnv, "")
+	writeSDK(t, root, "go1.22.5+boring")
+
+	downloads := filepath.Join(root, config.DownloadsDir)
+	now := time.Now()
+	files := []struct {
+		name string
+		age  time.Duration
+	}{
+		{"go1.23.0.linux-amd64.tar.gz", time.Hour},
+		{"go1.22.5.src.tar.gz", 2 * 24 * time.Hour}, // Source of the installed variant
+		{"go1.22.4.linux-amd64.tar.gz", 10 * 24 * time.Hour},
+		{"toolchain-v0.0.1-go1.21.13.linux-amd64.zip", 40 * 24 * time.Hour},
+		{"go1.24.0.linux-amd64.tar.gz" + PartSuffix, 2 * time.Hour}, // Abandoned
+		{"go1.24.1.linux-amd64.tar.gz" + PartSuffix, time.Minute},   // Still downloading
+		{filepath.Join("sub", "x.zip"+PartSuffix), 3 * time.Hour},
+		{filepath.Join("sub", "go1.19.linux-amd64.tar.gz"), 100 * 24 * time.Hour}, // Not an archive of ours
+	}
+	for _, f := range files {
+		path := filepath.Join(downloads, f.name)
+		writeFile(t, path, "data")
+		mtime := now.Add(-f.age)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	partials := []string{"go1.24.0.linux-amd64.tar.gz" + PartSuffix, filepath.Join("sub", "x.zip"+PartSuffix)}
+	tests := []struct {
+		desc string
+		opts CleanupOptions
+		want []string
+	}{
+		{"everything", CleanupOptions{}, []string{
+			"go1.23.0.linux-amd64.tar.gz", "go1.22.5.src.tar.gz", "go1.22.4.linux-amd64.tar.gz",
+			"toolchain-v0.0.1-go1.21.13.linux-amd64.zip",
+		}},
+		{"keep installed", CleanupOptions{KeepInstalled: true}, []string{
+			"go1.23.0.linux-amd64.tar.gz", "go1.22.4.linux-amd64.tar.gz",
+			"toolchain-v0.0.1-go1.21.13.linux-amd64.zip",
+		}},
+		{"keep 2 newest", CleanupOptions{Keep: 2}, []string{
+			"go1.22.4.linux-amd64.tar.gz", "toolchain-v0.0.1-go1.21.13.linux-amd64.zip",
+		}},
+		{"older than 7 days", CleanupOptions{OlderThan: 7 * 24 * time.Hour}, []string{
+			"go1.22.4.linux-amd64.tar.gz", "toolchain-v0.0.1-go1.21.13.linux-amd64.zip",
+		}},
+		{"all policies", CleanupOptions{KeepInstalled: true, Keep: 3, OlderThan: 30 * 24 * time.Hour}, []string{
+			"toolchain-v0.0.1-go1.21.13.linux-amd64.zip",
+		}},
+	}
+	for _, tt := range tests {
+		items, err := downloadsToClean(tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, item := range items {
+			got = append(got, item.name)
+		}
+		want := append(append([]string{}, partials...), tt.want...)
+		sort.Strings(got)
+		sort.Strings(want)
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("%s: downloadsToClean = %q, want %q", tt.desc, got, want)
+		}
+	}
+}
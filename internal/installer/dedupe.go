@@ -0,0 +1,198 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+// DedupeResult summarizes a run of Dedupe
+type DedupeResult struct {
+	Files int   // Files replaced with a hardlink
+	Saved int64 // Bytes freed
+}
+
+// errDedupeUnsupported is returned where files have no inodes to compare
+var errDedupeUnsupported = errors.New("goenv dedupe is not supported on this platform")
+
+// inode identifies a file independent of the paths linking to it
+type inode struct {
+	dev, ino uint64
+}
+
+// sdkFile is a file in the SDK directory with all the paths linking to it
+type sdkFile struct {
+	paths []string
+	size  int64
+	mode  fs.FileMode
+	nlink uint64
+}
+
+// Dedupe replaces identical files across the user's SDKs with read-only hardlinks
+// to a single copy. SDKs linked into goenv or from the system store are left alone,
+// and so are files also linked from outside the SDK directory, e.g. by goenv adopt
+// --mode hardlink, since making them read-only would change the original.
+func Dedupe() (*DedupeResult, error) {
+	sdkDir, err := config.GetSDKDir()
+	if err != nil {
+		return nil, err
+	}
+	installed, err := listSDKs(sdkDir, false)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[inode]*sdkFile)
+	var order []inode
+	for _, inst := range installed {
+		if info, err := os.Lstat(inst.Dir); err != nil || !info.IsDir() {
+			continue
+		}
+		err := filepath.WalkDir(inst.Dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			key, nlink, err := statInode(info)
+			if err != nil {
+				return err
+			}
+			f := files[key]
+			if f == nil {
+				f = &sdkFile{size: info.Size(), mode: info.Mode().Perm(), nlink: nlink}
+				files[key] = f
+				order = append(order, key)
+			}
+			f.paths = append(f.paths, path)
+			return nil
+		})
+		if errors.Is(err, errDedupeUnsupported) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", inst.Version, err)
+		}
+	}
+
+	// Only files on the same device with the same size and permissions can be linked.
+	// Write bits are ignored, files linked by an earlier run have lost theirs.
+	type group struct {
+		dev  uint64
+		size int64
+		mode fs.FileMode
+	}
+	groups := make(map[group][]inode)
+	var groupOrder []group
+	for _, key := range order {
+		f := files[key]
+		if f.size == 0 || f.nlink > uint64(len(f.paths)) {
+			continue
+		}
+		g := group{key.dev, f.size, f.mode &^ 0222}
+		if groups[g] == nil {
+			groupOrder = append(groupOrder, g)
+		}
+		groups[g] = append(groups[g], key)
+	}
+
+	result := &DedupeResult{}
+	for _, g := range groupOrder {
+		if len(groups[g]) < 2 {
+			continue
+		}
+		byHash := make(map[[sha256.Size]byte][]inode)
+		var hashes [][sha256.Size]byte
+		for _, key := range groups[g] {
+			sum, err := hashFile(files[key].paths[0])
+			if err != nil {
+				return result, err
+			}
+			if byHash[sum] == nil {
+				hashes = append(hashes, sum)
+			}
+			byHash[sum] = append(byHash[sum], key)
+		}
+		for _, sum := range hashes {
+			if len(byHash[sum]) < 2 {
+				continue
+			}
+			if err := linkDuplicates(files, byHash[sum], result); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// linkDuplicates links the paths of identical files to the one with the most links
+func linkDuplicates(files map[inode]*sdkFile, keys []inode, result *DedupeResult) error {
+	keep := files[keys[0]]
+	for _, key := range keys[1:] {
+		if len(files[key].paths) > len(keep.paths) {
+			keep = files[key]
+		}
+	}
+	// The copy is shared from now on, writing to it would change every SDK
+	if err := os.Chmod(keep.paths[0], keep.mode&^0222); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		f := files[key]
+		if f == keep {
+			continue
+		}
+		for _, path := range f.paths {
+			tmp := path + ".goenv-dedupe"
+			if err := os.Link(keep.paths[0], tmp); err != nil {
+				return fmt.Errorf("failed to link %s: %w", path, err)
+			}
+			if err := os.Rename(tmp, path); err != nil {
+				os.Remove(tmp)
+				return fmt.Errorf("failed to link %s: %w", path, err)
+			}
+			result.Files++
+		}
+		result.Saved += f.size
+	}
+	return nil
+}
+
+func hashFile(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// FormatBytes formats a size for humans, e.g. "1.5 GB"
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
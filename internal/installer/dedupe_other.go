@@ -0,0 +1,10 @@
+//go:build !unix
+
+package installer
+
+import "io/fs"
+
+// statInode fails, hardlinks cannot be told apart without inodes
+func statInode(info fs.FileInfo) (inode, uint64, error) {
+	return inode{}, 0, errDedupeUnsupported
+}
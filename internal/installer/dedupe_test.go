@@ -0,0 +1,54 @@
+//go:build unix
+
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDedupeAfterNewInstall(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("GOENV_ROOT", root)
+	t.Setenv("GOENV_SYSTEM_ROOT", "")
+
+	writeSDK(t, root, "go1.22.4")
+	writeSDK(t, root, "go1.22.5")
+	result, err := Dedupe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Files != 2 {
+		t.Errorf("first Dedupe linked %d files, want 2", result.Files)
+	}
+
+	// The next patch release comes with writable files, the shared copies are read-only by now
+	writeSDK(t, root, "go1.22.6")
+	result, err = Dedupe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Files != 2 {
+		t.Errorf("second Dedupe linked %d files, want 2", result.Files)
+	}
+
+	for _, name := range []string{"bin/go", "src/fmt/print.go"} {
+		shared, err := os.Stat(filepath.Join(root, "sdk", "go1.22.4", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range []string{"go1.22.5", "go1.22.6"} {
+			info, err := os.Stat(filepath.Join(root, "sdk", v, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !os.SameFile(shared, info) {
+				t.Errorf("%s of %s is not linked to go1.22.4", name, v)
+			}
+		}
+		if shared.Mode().Perm()&0222 != 0 {
+			t.Errorf("shared %s is writable: %v", name, shared.Mode())
+		}
+	}
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package installer
+
+import (
+	"fmt"
+	"io/fs"
+	"syscall"
+)
+
+// statInode returns the inode of a file and the number of links to it
+func statInode(info fs.FileInfo) (inode, uint64, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return inode{}, 0, fmt.Errorf("no inode for %s", info.Name())
+	}
+	return inode{uint64(stat.Dev), uint64(stat.Ino)}, uint64(stat.Nlink), nil
+}
@@ -0,0 +1,76 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+// GlobalEnvFile names the go env -w file shared by toolchains outside goenv in SeedEnvFile
+const GlobalEnvFile = "global"
+
+// EnvFile returns the file go env -w of version writes to, set as GOENV by its wrappers
+func EnvFile(version string) (string, error) {
+	root, err := config.GetGoenvRoot()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, version, "env"), nil
+}
+
+// globalEnvFile returns the go env -w file used outside goenv. $GOENV is not
+// consulted, inside goenv shell or exec it points at the env file of a version.
+func globalEnvFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the global go env file: %w", err)
+	}
+	return filepath.Join(dir, "go", "env"), nil
+}
+
+// SeedEnvFile copies the go env -w settings of from, GlobalEnvFile or an installed version,
+// into the env file of inst. An existing env file is only replaced if force is set.
+func SeedEnvFile(inst *Installation, from string, force bool) (string, error) {
+	dst, err := EnvFile(inst.Version)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(dst); err == nil && !force {
+		return "", fmt.Errorf("%s already exists, pass --force to replace it", dst)
+	}
+
+	var src string
+	if from == GlobalEnvFile {
+		src, err = globalEnvFile()
+		if err != nil {
+			return "", err
+		}
+	} else {
+		other, err := FindInstalled(from)
+		if err != nil {
+			return "", err
+		}
+		if src, err = EnvFile(other.Version); err != nil {
+			return "", err
+		}
+	}
+	if src == dst {
+		return "", fmt.Errorf("cannot copy the env file of %s onto itself", inst.Version)
+	}
+
+	if _, err := os.Stat(src); err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%s does not exist, nothing to copy", src)
+		}
+		return "", err
+	}
+	if err := config.EnsureDir(filepath.Dir(dst)); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(dst), err)
+	}
+	if err := copyFile(src, dst, 0644); err != nil {
+		return "", fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	return src, nil
+}
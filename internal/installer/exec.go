@@ -0,0 +1,134 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// Environ returns the current environment with the wrapper settings of inst applied
+// and a temporary bin directory prepended to PATH, in which go and gofmt resolve to inst.
+// The caller must call cleanup once the environment is no longer used.
+func Environ(inst *Installation) (env []string, cleanup func(), err error) {
+	wrapperEnv, err := WrapperEnv(inst.Version, inst.Dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	shimDir, err := os.MkdirTemp("", "goenv-"+inst.Version+"-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create shim directory: %w", err)
+	}
+	cleanup = func() { os.RemoveAll(shimDir) }
+
+	for _, tool := range []string{"go", "gofmt"} {
+		if err := os.Symlink(filepath.Join(inst.Dir, "bin", tool), filepath.Join(shimDir, tool)); err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to create %s shim: %w", tool, err)
+		}
+	}
+
+	overrides := map[string]string{
+		"PATH": shimDir + string(os.PathListSeparator) + os.Getenv("PATH"),
+	}
+	addWrapperEnv(overrides, wrapperEnv)
+	return mergeEnv(os.Environ(), overrides), cleanup, nil
+}
+
+// addWrapperEnv adds the wrapper environment to overrides. A GOTOOLCHAIN exported
+// by the user takes precedence over the one of the wrappers.
+func addWrapperEnv(overrides map[string]string, env []EnvVar) {
+	for _, e := range env {
+		if e.Name == "GOTOOLCHAIN" && os.Getenv("GOTOOLCHAIN") != "" {
+			continue
+		}
+		overrides[e.Name] = e.Value
+	}
+}
+
+// lookupEnv returns the value of name in env
+func lookupEnv(env []string, name string) string {
+	for _, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == name {
+			return v
+		}
+	}
+	return ""
+}
+
+// mergeEnv returns env with the values in overrides replacing or extending it
+func mergeEnv(env []string, overrides map[string]string) []string {
+	merged := make([]string, 0, len(env)+len(overrides))
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := overrides[name]; ok {
+			continue
+		}
+		merged = append(merged, kv)
+	}
+	for name, value := range overrides {
+		merged = append(merged, name+"="+value)
+	}
+	return merged
+}
+
+// Run runs name with args in env and returns its exit code.
+// name is looked up in the PATH of env, interrupts are left to the child to handle.
+func Run(env []string, name string, args ...string) (int, error) {
+	path, err := lookPath(name, env)
+	if err != nil {
+		return 0, err
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Args[0] = name
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// The terminal delivers interrupts to the child as well, we just wait for it to exit
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			// Report death by signal the way shells do
+			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+				return 128 + int(status.Signal()), nil
+			}
+			return exitErr.ExitCode(), nil
+		}
+		return 0, err
+	}
+	return 0, nil
+}
+
+// lookPath searches name in the PATH of env rather than that of the current process
+func lookPath(name string, env []string) (string, error) {
+	if strings.ContainsRune(name, filepath.Separator) {
+		return name, nil
+	}
+
+	var pathList string
+	for _, kv := range env {
+		if value, ok := strings.CutPrefix(kv, "PATH="); ok {
+			pathList = value
+		}
+	}
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" {
+			dir = "."
+		}
+		candidate := filepath.Join(dir, name)
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() && info.Mode()&0111 != 0 {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%s: command not found", name)
+}
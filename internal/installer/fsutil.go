@@ -0,0 +1,134 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// linkTree recreates the tree at src under dst, hardlinking regular files.
+// Files that cannot be linked, e.g. because dst is on another device, are copied.
+func linkTree(src, dst string) error {
+	return walkTree(src, dst, func(from, to string, info fs.FileInfo) error {
+		if err := os.Link(from, to); err == nil {
+			return nil
+		}
+		return copyFile(from, to, info.Mode().Perm())
+	})
+}
+
+// copyTree recreates the tree at src under dst, copying regular files
+func copyTree(src, dst string) error {
+	return walkTree(src, dst, func(from, to string, info fs.FileInfo) error {
+		return copyFile(from, to, info.Mode().Perm())
+	})
+}
+
+// moveTree renames src to dst, copying and removing src if they are on different devices
+func moveTree(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := copyTree(src, dst); err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	return removeTree(src)
+}
+
+// removeTree removes path like os.RemoveAll, first making read-only directories
+// writable, as found in module caches
+func removeTree(path string) error {
+	filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			os.Chmod(p, 0755)
+		}
+		return nil
+	})
+	return os.RemoveAll(path)
+}
+
+// rebaseSymlinks repoints the symlinks in dirs whose targets rebase maps elsewhere,
+// e.g. SDKs adopted from a directory within a goenv tree that has moved
+func rebaseSymlinks(dirs []string, rebase func(path string) (string, bool)) error {
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.Type()&fs.ModeSymlink == 0 {
+				return err
+			}
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			target, ok := rebase(link)
+			if !ok {
+				return nil
+			}
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			return os.Symlink(target, path)
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to update symlinks: %w", err)
+		}
+	}
+	return nil
+}
+
+// walkTree mirrors the directories and symlinks of src under dst and calls
+// file for every regular file. Directories are created writable so the
+// result can be managed by goenv even if the source is read-only.
+func walkTree(src, dst string, file func(from, to string, info fs.FileInfo) error) error {
+	return filepath.Walk(src, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, 0755)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return file(path, target, info)
+		}
+		return nil
+	})
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	// dst may be a hardlink shared with other files, replace it rather than writing through it
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
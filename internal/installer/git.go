@@ -0,0 +1,170 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+const (
+	DevVersionPrefix = "godev-" // Prefix of toolchains built from a git checkout
+	shortSHALen      = 10
+)
+
+// InstallFromGit builds a Go toolchain from a local clone of the go repository
+// at the given ref and registers it as godev-<shortsha>.
+// The build runs in a temporary worktree, so the clone itself is left untouched.
+func InstallFromGit(repoDir, ref, bootstrap string) error {
+	repoDir, err := filepath.Abs(repoDir)
+	if err != nil {
+		return err
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	commit, err := gitOutput(repoDir, "rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s in %s: %w", ref, repoDir, err)
+	}
+	version := DevVersionPrefix + commit[:shortSHALen]
+
+	sdkDir, err := config.GetSDKDir()
+	if err != nil {
+		return err
+	}
+	if err := config.EnsureDir(sdkDir); err != nil {
+		return fmt.Errorf("failed to create SDK directory: %w", err)
+	}
+
+	binDir, err := config.GetBinDir()
+	if err != nil {
+		return err
+	}
+	if err := config.EnsureDir(binDir); err != nil {
+		return fmt.Errorf("failed to create bin directory: %w", err)
+	}
+
+	installDir := filepath.Join(sdkDir, version)
+	if _, err := os.Stat(filepath.Join(installDir, "bin", "go")); err == nil {
+		fmt.Printf("%s (commit %s) is already installed.\n", version, commit)
+		return nil
+	}
+
+	boot, err := findBootstrap(bootstrap)
+	if err != nil {
+		return err
+	}
+
+	buildDir, err := config.GetBuildDir()
+	if err != nil {
+		return err
+	}
+	if err := config.EnsureDir(buildDir); err != nil {
+		return fmt.Errorf("failed to create build directory: %w", err)
+	}
+
+	// Start from a clean worktree, a previous build may have been interrupted
+	worktree := filepath.Join(buildDir, version)
+	if err := os.RemoveAll(worktree); err != nil {
+		return fmt.Errorf("failed to remove stale worktree: %w", err)
+	}
+	if _, err := gitOutput(repoDir, "worktree", "prune"); err != nil {
+		return fmt.Errorf("failed to prune worktrees: %w", err)
+	}
+
+	fmt.Printf("Building %s from %s (%s) with bootstrap %s...\n", version, repoDir, ref, boot.Version)
+	if _, err := gitOutput(repoDir, "worktree", "add", "--detach", worktree, commit); err != nil {
+		return fmt.Errorf("failed to create worktree: %w", err)
+	}
+	defer removeWorktree(repoDir, worktree)
+
+	if err := buildFromSource(worktree, boot.Dir, nil); err != nil {
+		return fmt.Errorf("failed to build: %w", err)
+	}
+
+	// Detach the built tree from the clone and move it into the SDK directory,
+	// removeWorktree then prunes the dangling worktree metadata.
+	if err := os.Remove(filepath.Join(worktree, ".git")); err != nil {
+		return fmt.Errorf("failed to detach worktree: %w", err)
+	}
+	if err := os.Rename(worktree, installDir); err != nil {
+		return fmt.Errorf("failed to move build into SDK directory: %w", err)
+	}
+
+	if err := createGoScript(version, installDir, binDir); err != nil {
+		return fmt.Errorf("failed to create go script: %w", err)
+	}
+	if err := createGofmtScript(version, installDir, binDir); err != nil {
+		return fmt.Errorf("failed to create gofmt script: %w", err)
+	}
+
+	receipt := &Receipt{
+		Version:     version,
+		Source:      SourceGit,
+		GitRepo:     repoDir,
+		GitRef:      ref,
+		GitCommit:   commit,
+		Bootstrap:   boot.Version,
+		InstalledAt: time.Now(),
+	}
+	if err := SaveReceipt(receipt); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully installed %s (commit %s)\n", version, commit)
+	fmt.Printf("Use '%s' to run this version of Go\n", version)
+	afterInstall(version)
+	return nil
+}
+
+// buildFromSource runs make.bash in goroot using the bootstrap toolchain.
+// extraEnv is appended to the build environment, e.g. GOEXPERIMENT settings.
+func buildFromSource(goroot, bootstrapRoot string, extraEnv []string) error {
+	cmd := exec.Command("./make.bash")
+	cmd.Dir = filepath.Join(goroot, "src")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// Settings of the calling environment must not leak into the build
+	var env []string
+	for _, kv := range os.Environ() {
+		switch strings.SplitN(kv, "=", 2)[0] {
+		case "GOROOT", "GOBIN", "GOFLAGS", "GOEXPERIMENT", "GOTOOLCHAIN", "GOROOT_BOOTSTRAP":
+			continue
+		}
+		env = append(env, kv)
+	}
+	env = append(env, "GOROOT_BOOTSTRAP="+bootstrapRoot, "GOTOOLCHAIN=local")
+	cmd.Env = append(env, extraEnv...)
+
+	return cmd.Run()
+}
+
+func removeWorktree(repoDir, worktree string) {
+	if _, err := os.Stat(worktree); err == nil {
+		if _, err := gitOutput(repoDir, "worktree", "remove", "--force", worktree); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove worktree %s: %v\n", worktree, err)
+		}
+	}
+	if _, err := gitOutput(repoDir, "worktree", "prune"); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to prune worktrees: %v\n", err)
+	}
+}
+
+func gitOutput(repoDir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", repoDir}, args...)...)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
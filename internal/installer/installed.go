@@ -0,0 +1,181 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+	"github.com/hitzhangjie/goenv/internal/project"
+	"github.com/hitzhangjie/goenv/internal/version"
+)
+
+// Installation describes an installed Go SDK
+type Installation struct {
+	Version string // e.g., "go1.22.5" or "godev-1a2b3c4d5e"
+	Dir     string // GOROOT of the SDK
+	System  bool   // The SDK comes from the shared system store rather than the user's goenv root
+}
+
+// ListInstalled returns all installed Go SDKs, sorted by name.
+// SDKs of the system store are included unless the user installed the same version.
+func ListInstalled() ([]Installation, error) {
+	sdkDir, err := config.GetSDKDir()
+	if err != nil {
+		return nil, err
+	}
+	installed, err := listSDKs(sdkDir, false)
+	if err != nil {
+		return nil, err
+	}
+
+	systemRoot, err := config.GetSystemRoot()
+	if err != nil || systemRoot == "" {
+		return installed, err
+	}
+	system, err := listSDKs(filepath.Join(systemRoot, config.SDKDir), true)
+	if err != nil {
+		return nil, err
+	}
+	own := make(map[string]bool, len(installed))
+	for _, inst := range installed {
+		own[inst.Version] = true
+	}
+	for _, inst := range system {
+		if !own[inst.Version] {
+			installed = append(installed, inst)
+		}
+	}
+	sort.Slice(installed, func(i, j int) bool {
+		return installed[i].Version < installed[j].Version
+	})
+	return installed, nil
+}
+
+// listSDKs returns the SDKs in sdkDir
+func listSDKs(sdkDir string, system bool) ([]Installation, error) {
+	entries, err := os.ReadDir(sdkDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read SDK directory: %w", err)
+	}
+
+	var installed []Installation
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), "go") {
+			continue
+		}
+		dir := filepath.Join(sdkDir, entry.Name())
+		// Verify it's a valid installation by checking for bin/go
+		if _, err := os.Stat(filepath.Join(dir, "bin", "go")); err != nil {
+			continue
+		}
+		installed = append(installed, Installation{Version: entry.Name(), Dir: dir, System: system})
+	}
+	return installed, nil
+}
+
+// FindInstalled looks up an installed Go SDK by version
+func FindInstalled(v string) (*Installation, error) {
+	v = version.NormalizeVersion(v)
+
+	installed, err := ListInstalled()
+	if err != nil {
+		return nil, err
+	}
+	for i := range installed {
+		if installed[i].Version == v {
+			return &installed[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not installed, run 'goenv install %s' first", v, v)
+}
+
+// devVersionRegex matches the commit suffix of development builds
+var devVersionRegex = regexp.MustCompile(`^[0-9a-f]+$`)
+
+// releaseNameRegex matches releases, including betas the version list does not parse
+var releaseNameRegex = regexp.MustCompile(`^go[0-9]+(\.[0-9]+)*((rc|beta)[0-9]+)?$`)
+
+// isVersionName reports whether name is a release, a variant or a development
+// build. It only looks at the name, goenv runs it on every invocation.
+func isVersionName(name string) bool {
+	if rest, ok := strings.CutPrefix(name, DevVersionPrefix); ok {
+		return devVersionRegex.MatchString(rest)
+	}
+	if _, variant := version.SplitVariant(name); variant != "" {
+		return version.ValidateVariant(name) == nil
+	}
+	return releaseNameRegex.MatchString(name)
+}
+
+// ErrNotPinned is returned by FindPinned if no Go version is pinned for a directory
+var ErrNotPinned = errors.New("no Go version is pinned")
+
+// FindPinned resolves the Go version pinned for dir to an installed SDK.
+// The pin is returned even if no installed SDK satisfies it, together with an error.
+func FindPinned(dir string) (*Installation, *project.Pin, error) {
+	pin, err := project.Resolve(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pin == nil {
+		return nil, nil, fmt.Errorf("%w for %s (no .go-version, .tool-versions or go.mod found)", ErrNotPinned, dir)
+	}
+
+	installed, err := ListInstalled()
+	if err != nil {
+		return nil, pin, err
+	}
+	var names []string
+	for _, inst := range installed {
+		names = append(names, inst.Version)
+	}
+
+	match := pin.Match(names)
+	if match == "" {
+		required := pin.Required()
+		return nil, pin, fmt.Errorf("%s requested by %s is not installed, run 'goenv install %s'", pin.Version, pin.Describe(), required)
+	}
+	for i := range installed {
+		if installed[i].Version == match {
+			return &installed[i], pin, nil
+		}
+	}
+	return nil, pin, fmt.Errorf("%s is not installed", match)
+}
+
+// findBootstrap returns the GOROOT used to bootstrap source builds.
+// If v is empty, the newest installed release is used.
+func findBootstrap(v string) (*Installation, error) {
+	if v != "" {
+		return FindInstalled(v)
+	}
+
+	installed, err := ListInstalled()
+	if err != nil {
+		return nil, err
+	}
+
+	var best *Installation
+	var bestVersion *version.Version
+	for i := range installed {
+		parsed, err := version.ParseVersion(installed[i].Version)
+		if err != nil || parsed.IsRC {
+			continue
+		}
+		if bestVersion == nil || parsed.Compare(bestVersion) > 0 {
+			best, bestVersion = &installed[i], parsed
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no installed Go release found to bootstrap the build, install one first or pass --bootstrap")
+	}
+	return best, nil
+}
@@ -8,11 +8,14 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hitzhangjie/goenv/internal/config"
 	"github.com/hitzhangjie/goenv/internal/system"
+	"github.com/hitzhangjie/goenv/internal/version"
 )
 
 // Install installs a Go version
@@ -33,8 +36,13 @@ func Install(version string) error {
 		return fmt.Errorf("failed to get GOARCH: %w", err)
 	}
 
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return err
+	}
+
 	// Construct download URL
-	url := system.GetDownloadURL(version, goos, goarch)
+	url := system.GetDownloadURL(settings.Mirror, version, goos, goarch)
 	fmt.Printf("Installing %s for %s/%s...\n", version, goos, goarch)
 	fmt.Printf("Download URL: %s\n", url)
 
@@ -63,6 +71,12 @@ func Install(version string) error {
 		return fmt.Errorf("failed to create bin directory: %w", err)
 	}
 
+	installDir := filepath.Join(sdkDir, version)
+	if _, err := os.Stat(filepath.Join(installDir, "bin", "go")); err == nil {
+		fmt.Printf("%s is already installed.\n", version)
+		return nil
+	}
+
 	// Download (check if already exists first)
 	tarballPath := filepath.Join(downloadsDir, filepath.Base(url))
 	if _, err := os.Stat(tarballPath); err == nil {
@@ -74,7 +88,6 @@ func Install(version string) error {
 	}
 
 	// Extract
-	installDir := filepath.Join(sdkDir, version)
 	if err := extractTarball(tarballPath, installDir); err != nil {
 		return fmt.Errorf("failed to extract: %w", err)
 	}
@@ -88,13 +101,38 @@ func Install(version string) error {
 		return fmt.Errorf("failed to create gofmt script: %w", err)
 	}
 
+	receipt := &Receipt{
+		Version:     version,
+		Source:      SourceDownload,
+		URL:         url,
+		InstalledAt: time.Now(),
+	}
+	if err := SaveReceipt(receipt); err != nil {
+		return err
+	}
+
 	fmt.Printf("Successfully installed %s\n", version)
 	fmt.Printf("Use '%s' to run this version of Go\n", version)
 
+	afterInstall(version)
 	return nil
 }
 
+// PartSuffix marks a download in progress, it is renamed to its final name once complete
+const PartSuffix = ".part"
+
+// downloadFile downloads url to dest. The data goes to dest.part first, so an
+// interrupted download never looks like a complete archive.
 func downloadFile(url, dest string) error {
+	part := dest + PartSuffix
+	if err := fetchFile(url, part); err != nil {
+		os.Remove(part)
+		return err
+	}
+	return os.Rename(part, dest)
+}
+
+func fetchFile(url, dest string) error {
 	fmt.Printf("Downloading %s...\n", url)
 
 	client := &http.Client{
@@ -217,8 +255,12 @@ func extractTarball(tarballPath, destDir string) error {
 			continue
 		}
 
-		// Create file
-		outFile, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
+		// Create file, replacing rather than overwriting an existing one, which
+		// may be a hardlink shared with other SDKs (see goenv dedupe)
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		outFile, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(header.Mode))
 		if err != nil {
 			return err
 		}
@@ -236,91 +278,217 @@ func extractTarball(tarballPath, destDir string) error {
 // FixScripts regenerates wrapper scripts for all installed Go versions.
 // Use this after goenv itself is updated to apply new environment variable settings.
 func FixScripts() error {
-	sdkDir, err := config.GetSDKDir()
+	binDir, err := config.GetBinDir()
 	if err != nil {
 		return err
 	}
-
-	binDir, err := config.GetBinDir()
-	if err != nil {
+	// Report a broken config once rather than for the first version
+	if _, err := config.LoadSettings(); err != nil {
 		return err
 	}
 
-	entries, err := os.ReadDir(sdkDir)
+	installed, err := ListInstalled()
 	if err != nil {
-		return fmt.Errorf("failed to read SDK directory: %w", err)
+		return err
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "go") {
-			continue
-		}
-		version := entry.Name()
-		installDir := filepath.Join(sdkDir, version)
-		goBin := filepath.Join(installDir, "bin", "go")
-		if _, err := os.Stat(goBin); err != nil {
-			continue
+	for _, inst := range installed {
+		if err := createGoScript(inst.Version, inst.Dir, binDir); err != nil {
+			return fmt.Errorf("failed to fix script for %s: %w", inst.Version, err)
 		}
-		if err := createGoScript(version, installDir, binDir); err != nil {
-			return fmt.Errorf("failed to fix script for %s: %w", version, err)
+		if err := createGofmtScript(inst.Version, inst.Dir, binDir); err != nil {
+			return fmt.Errorf("failed to fix gofmt script for %s: %w", inst.Version, err)
 		}
-		if err := createGofmtScript(version, installDir, binDir); err != nil {
-			return fmt.Errorf("failed to fix gofmt script for %s: %w", version, err)
-		}
-		fmt.Printf("Fixed scripts for %s\n", version)
+		fmt.Printf("Fixed scripts for %s\n", inst.Version)
 	}
 
-	return nil
+	if err := fixShimDirs(); err != nil {
+		return err
+	}
+	if err := fixToolShims(installed, binDir); err != nil {
+		return err
+	}
+	return fixDefaultScripts(binDir)
 }
 
-func createGoScript(version, installDir, binDir string) error {
-	scriptPath := filepath.Join(binDir, version)
+// EnvVar is an environment variable set by the wrappers
+type EnvVar struct {
+	Name  string
+	Value string
+}
 
+// WrapperEnv returns the environment the wrappers of version set before running
+// the real go command: its own GOROOT, GOPATH, GOBIN and go env -w file under
+// ~/.goenv/<version>, its own build caches unless they are shared, followed by
+// the variables configured for the version and GOTOOLCHAIN, sorted by name.
+// The wrappers only set GOTOOLCHAIN if the user did not export it.
+func WrapperEnv(version, installDir string) ([]EnvVar, error) {
 	root, err := config.GetGoenvRoot()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	cacheRoot, err := config.GetCacheRoot()
+	if err != nil {
+		return nil, err
+	}
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return nil, err
 	}
 
-	goroot := installDir
 	gopath := filepath.Join(root, version)
-	gobin := filepath.Join(gopath, "bin")
-	gocache := filepath.Join(gopath, "cache")
-	gotestcache := filepath.Join(gopath, "testcache")
-	goBin := filepath.Join(installDir, "bin", "go")
+	cacheDir := filepath.Join(cacheRoot, version)
+	gocache := filepath.Join(cacheDir, "cache")
+	if settings.Cache.GoCache != "" {
+		gocache = settings.Cache.GoCache
+	}
+	env := []EnvVar{
+		{"GOROOT", installDir},
+		{"GOPATH", gopath},
+		{"GOBIN", filepath.Join(gopath, "bin")},
+		{"GOCACHE", gocache},
+		{"GOTESTCACHE", filepath.Join(cacheDir, "testcache")},
+		{"GOENV", filepath.Join(gopath, "env")},
+	}
+	if settings.Cache.ModCache != "" {
+		env = append(env, EnvVar{"GOMODCACHE", settings.Cache.ModCache})
+	}
 
-	script := fmt.Sprintf(`#!/bin/bash
-GOROOT="%s" GOPATH="%s" GOBIN="%s" GOCACHE="%s" GOTESTCACHE="%s" exec "%s" "$@"
-`, goroot, gopath, gobin, gocache, gotestcache, goBin)
+	custom := settings.Env.Lookup(version, versionLine(version))
+	if _, ok := custom["GOTOOLCHAIN"]; !ok {
+		custom["GOTOOLCHAIN"] = config.DefaultToolchain
+	}
+	names := make([]string, 0, len(custom))
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, EnvVar{name, custom[name]})
+	}
+	return env, nil
+}
+
+// versionLine returns the minor line of an installed version, e.g. "go1.22" for
+// "go1.22.5+boring", or "" for development builds
+func versionLine(name string) string {
+	base, _ := version.SplitVariant(name)
+	v, err := version.ParseVersion(base)
+	if err != nil {
+		return ""
+	}
+	return "go" + v.GetMajorMinor()
+}
+
+func createGoScript(version, installDir, binDir string) error {
+	if installingToStore {
+		return nil
+	}
+	return writeGoScript(filepath.Join(binDir, version), version, installDir)
+}
 
-	if err := os.WriteFile(scriptPath, []byte(script), 0755); err != nil {
+// writeGoScript writes a wrapper at scriptPath running the go command of version
+func writeGoScript(scriptPath, version, installDir string) error {
+	env, err := WrapperEnv(version, installDir)
+	if err != nil {
+		return err
+	}
+	q, err := newWrapperQuoter(scriptPath)
+	if err != nil {
 		return err
 	}
+	goBin := filepath.Join(installDir, "bin", "go")
 
-	return nil
+	var assignments []string
+	for _, e := range env {
+		assignments = append(assignments, q.assignment(e))
+	}
+	script := fmt.Sprintf(`#!/bin/bash
+%s%s exec %s "$@"
+`, q.prelude(), strings.Join(assignments, " "), q.quote(goBin))
+
+	return writeWrapper(scriptPath, script)
 }
 
 func createGofmtScript(version, installDir, binDir string) error {
-	suffix := strings.TrimPrefix(version, "go")
-	scriptPath := filepath.Join(binDir, "gofmt"+suffix)
-	gofmtBin := filepath.Join(installDir, "bin", "gofmt")
+	if installingToStore {
+		return nil
+	}
+	if strings.HasPrefix(version, DevVersionPrefix) {
+		// Named gofmtdev-<commit> before development builds got their own scheme
+		os.Remove(filepath.Join(binDir, "gofmt"+strings.TrimPrefix(version, "go")))
+	}
+	return writeGofmtScript(filepath.Join(binDir, gofmtWrapperName(version)), installDir)
+}
 
-	// Check if gofmt script already exists
-	if _, err := os.Stat(scriptPath); err == nil {
-		// Script exists, check if it points to the same version
-		data, err := os.ReadFile(scriptPath)
-		if err == nil && strings.Contains(string(data), gofmtBin) {
-			// Already points to this version, skip
-			return nil
-		}
+// gofmtWrapperName returns the name of the gofmt wrapper of version, e.g.
+// gofmt1.22.5, or gofmt-godev-f52d441ca8 for development builds, where the
+// version without its go prefix would not be recognizable
+func gofmtWrapperName(version string) string {
+	if strings.HasPrefix(version, DevVersionPrefix) {
+		return "gofmt-" + version
 	}
+	return "gofmt" + strings.TrimPrefix(version, "go")
+}
+
+// writeGofmtScript writes a wrapper at scriptPath running the gofmt of the SDK in installDir
+func writeGofmtScript(scriptPath, installDir string) error {
+	q, err := newWrapperQuoter(scriptPath)
+	if err != nil {
+		return err
+	}
+	gofmtBin := filepath.Join(installDir, "bin", "gofmt")
 
 	script := fmt.Sprintf(`#!/bin/bash
-exec "%s" "$@"
-`, gofmtBin)
+%sexec %s "$@"
+`, q.prelude(), q.quote(gofmtBin))
 
-	if err := os.WriteFile(scriptPath, []byte(script), 0755); err != nil {
+	return writeWrapper(scriptPath, script)
+}
+
+// shimWarning reports once that wrappers cannot be linked to the goenv binary
+var shimWarning sync.Once
+
+// writeWrapper writes the wrapper at path according to the configured wrapper mode:
+// a symlink to the goenv binary in shim mode, otherwise the given script. Versions
+// with names goenv cannot recognize when invoked, e.g. adopted with an unusual
+// VERSION file, always get the script. An existing wrapper is replaced rather than
+// written through, it may be a symlink.
+func writeWrapper(path, script string) error {
+	settings, err := config.LoadSettings()
+	if err != nil {
 		return err
 	}
 
-	return nil
+	if settings.WrapperMode == config.WrapperShim && IsShim(path) {
+		exe, err := shimTarget()
+		if err == nil {
+			if current, err := os.Readlink(path); err == nil && current == exe {
+				return nil
+			}
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return os.Symlink(exe, path)
+		}
+		shimWarning.Do(func() {
+			fmt.Fprintf(os.Stderr, "Warning: cannot link shims (%v), writing scripts instead.\n", err)
+		})
+	}
+
+	if data, err := os.ReadFile(path); err == nil && string(data) == script {
+		if info, err := os.Lstat(path); err == nil && info.Mode().IsRegular() {
+			// Already up to date, skip
+			return nil
+		}
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.WriteFile(path, []byte(script), 0755)
+}
+
+// shellQuote quotes s as a single word for bash
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
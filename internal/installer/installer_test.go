@@ -0,0 +1,119 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+func TestWrapperEnv(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("GOENV_ROOT", root)
+	t.Setenv("GOENV_SYSTEM_ROOT", "")
+
+	settings := &config.Settings{
+		Env: config.EnvSettings{
+			Global:   map[string]string{"GOPRIVATE": "git.example.com", "CGO_ENABLED": "1"},
+			Lines:    map[string]map[string]string{"go1.22": {"GOTOOLCHAIN": "auto", "GOFLAGS": "-mod=mod"}},
+			Versions: map[string]map[string]string{"go1.22.5+boring": {"CGO_ENABLED": "0"}},
+		},
+		Cache: config.CacheSettings{ModCache: "/shared/mod"},
+	}
+	if err := config.SaveSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	sdk := filepath.Join(root, "sdk", "go1.22.5+boring")
+	gopath := filepath.Join(root, "go1.22.5+boring")
+	tests := []struct {
+		version string
+		want    []EnvVar
+	}{
+		// Managed variables first, then the configured ones sorted by name
+		{"go1.22.5+boring", []EnvVar{
+			{"GOROOT", sdk},
+			{"GOPATH", gopath},
+			{"GOBIN", filepath.Join(gopath, "bin")},
+			{"GOCACHE", filepath.Join(gopath, "cache")},
+			{"GOTESTCACHE", filepath.Join(gopath, "testcache")},
+			{"GOENV", filepath.Join(gopath, "env")},
+			{"GOMODCACHE", "/shared/mod"},
+			{"CGO_ENABLED", "0"},
+			{"GOFLAGS", "-mod=mod"},
+			{"GOPRIVATE", "git.example.com"},
+			{"GOTOOLCHAIN", "auto"},
+		}},
+		// Without a configured GOTOOLCHAIN the wrappers stay on their own SDK
+		{"go1.21.13", []EnvVar{
+			{"GOROOT", sdk},
+			{"GOPATH", filepath.Join(root, "go1.21.13")},
+			{"GOBIN", filepath.Join(root, "go1.21.13", "bin")},
+			{"GOCACHE", filepath.Join(root, "go1.21.13", "cache")},
+			{"GOTESTCACHE", filepath.Join(root, "go1.21.13", "testcache")},
+			{"GOENV", filepath.Join(root, "go1.21.13", "env")},
+			{"GOMODCACHE", "/shared/mod"},
+			{"CGO_ENABLED", "1"},
+			{"GOPRIVATE", "git.example.com"},
+			{"GOTOOLCHAIN", config.DefaultToolchain},
+		}},
+	}
+	for _, tt := range tests {
+		env, err := WrapperEnv(tt.version, sdk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(env) != len(tt.want) {
+			t.Errorf("WrapperEnv(%s) = %v, want %v", tt.version, env, tt.want)
+			continue
+		}
+		for i := range env {
+			if env[i] != tt.want[i] {
+				t.Errorf("WrapperEnv(%s)[%d] = %v, want %v", tt.version, i, env[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestWriteWrapper(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("GOENV_ROOT", root)
+	if err := config.SaveSettings(&config.Settings{WrapperMode: config.WrapperShim}); err != nil {
+		t.Fatal(err)
+	}
+	// go test runs a temporary binary, which shims must never point to
+	if exe, err := shimTarget(); err == nil {
+		t.Fatalf("shimTarget accepted the test binary %s", exe)
+	}
+
+	// A previous shim links to a binary, writing the script must not go through the link
+	target := filepath.Join(root, "goenv")
+	if err := os.WriteFile(target, []byte("binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(root, "go1.22.5")
+	if err := os.Symlink(target, path); err != nil {
+		t.Fatal(err)
+	}
+
+	script := "#!/bin/bash\nexec go \"$@\"\n"
+	for i := 0; i < 2; i++ {
+		if err := writeWrapper(path, script); err != nil {
+			t.Fatal(err)
+		}
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+		t.Errorf("wrapper is %v, want an executable script", info.Mode())
+	}
+	if data, _ := os.ReadFile(path); string(data) != script {
+		t.Errorf("wrapper contains %q, want %q", data, script)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "binary" {
+		t.Errorf("writing the wrapper changed the old link target to %q", data)
+	}
+}
@@ -0,0 +1,201 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+// cacheEntries are the entries of the goenv root that belong in the cache directory
+var cacheEntries = map[string]bool{
+	config.VersionsFile: true,
+	config.DownloadsDir: true,
+	config.BuildDir:     true,
+	config.SumDBDir:     true,
+	config.ModCacheDir:  true,
+	config.GoCacheDir:   true,
+}
+
+// versionCaches are the build caches in the per-version directories
+var versionCaches = []string{"cache", "testcache"}
+
+// MigrateLayout moves the goenv files from the layout in use to the named layout,
+// updates shared cache settings pointing into the old tree and regenerates the wrappers.
+func MigrateLayout(name string) error {
+	for _, env := range []string{config.RootEnv, config.LayoutEnv} {
+		if os.Getenv(env) != "" {
+			return fmt.Errorf("%s is set and decides the layout, unset it before migrating", env)
+		}
+	}
+
+	from, err := config.GetLayout()
+	if err != nil {
+		return err
+	}
+	to, err := config.LayoutDirs(name)
+	if err != nil {
+		return err
+	}
+	if from.Name == to.Name {
+		return fmt.Errorf("goenv already uses the %s layout", name)
+	}
+	if _, err := os.Stat(from.DataDir); os.IsNotExist(err) {
+		return fmt.Errorf("nothing to migrate, %s does not exist", from.DataDir)
+	}
+
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range uniqueDirs(from) {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			dst := filepath.Join(layoutDirFor(to, entry.Name()), entry.Name())
+			if err := moveInto(filepath.Join(dir, entry.Name()), dst); err != nil {
+				return fmt.Errorf("failed to move %s: %w", entry.Name(), err)
+			}
+		}
+	}
+
+	// Per-version build caches move out of the per-version GOPATHs
+	if to.CacheDir != to.DataDir {
+		entries, err := os.ReadDir(to.DataDir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "go") || cacheEntries[entry.Name()] {
+				continue
+			}
+			for _, cache := range versionCaches {
+				src := filepath.Join(to.DataDir, entry.Name(), cache)
+				if _, err := os.Stat(src); err != nil {
+					continue
+				}
+				if err := moveInto(src, filepath.Join(to.CacheDir, entry.Name(), cache)); err != nil {
+					return fmt.Errorf("failed to move %s: %w", src, err)
+				}
+			}
+		}
+	}
+
+	// Symlinks and receipts pointing into the old tree, e.g. for SDKs adopted from a directory within it
+	rebase := func(path string) (string, bool) {
+		if !filepath.IsAbs(path) {
+			return path, false
+		}
+		for _, dir := range uniqueDirs(from) {
+			if !isInside(dir, path) {
+				continue
+			}
+			rel, _ := filepath.Rel(dir, path)
+			elems := strings.Split(rel, string(filepath.Separator))
+			base := layoutDirFor(to, elems[0])
+			if len(elems) > 1 && base == to.DataDir && slices.Contains(versionCaches, elems[1]) {
+				base = to.CacheDir
+			}
+			return filepath.Join(base, rel), true
+		}
+		return path, false
+	}
+	if err := rebaseSymlinks(uniqueDirs(to), rebase); err != nil {
+		return err
+	}
+
+	// The old directories are empty now, the data directory must go for the new layout to be detected
+	for _, dir := range uniqueDirs(from) {
+		if err := os.Remove(dir); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", dir, err)
+		}
+	}
+	fmt.Printf("Moved goenv files to the %s layout:\n  config: %s\n  cache:  %s\n  data:   %s\n", to.Name, to.ConfigDir, to.CacheDir, to.DataDir)
+
+	if err := rebaseReceipts(rebase); err != nil {
+		return err
+	}
+
+	changed := false
+	for _, c := range []struct {
+		value *string
+		name  string
+	}{
+		{&settings.Cache.ModCache, config.ModCacheDir},
+		{&settings.Cache.GoCache, config.GoCacheDir},
+	} {
+		if *c.value == filepath.Join(from.CacheDir, c.name) {
+			*c.value, changed = filepath.Join(to.CacheDir, c.name), true
+		}
+	}
+	if changed {
+		if err := config.SaveSettings(settings); err != nil {
+			return err
+		}
+	}
+
+	return FixScripts()
+}
+
+// uniqueDirs returns the distinct directories of a layout, data directory last
+func uniqueDirs(layout *config.Layout) []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, dir := range []string{layout.ConfigDir, layout.CacheDir, layout.DataDir} {
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// layoutDirFor returns the directory of layout an entry of the goenv root belongs in
+func layoutDirFor(layout *config.Layout, name string) string {
+	switch {
+	case name == config.ConfigFile:
+		return layout.ConfigDir
+	case cacheEntries[name]:
+		return layout.CacheDir
+	}
+	return layout.DataDir
+}
+
+// moveInto moves src to dst, merging directories that exist on both sides
+func moveInto(src, dst string) error {
+	if src == dst {
+		return nil
+	}
+	info, err := os.Lstat(dst)
+	if os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		return moveTree(src, dst)
+	}
+	if err != nil {
+		return err
+	}
+
+	srcInfo, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() || !srcInfo.IsDir() {
+		return fmt.Errorf("%s already exists", dst)
+	}
+	if _, err := mergeTree(src, dst); err != nil {
+		return err
+	}
+	// What is left exists in dst already
+	return removeTree(src)
+}
@@ -0,0 +1,169 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+// isolateHome points HOME and the XDG directories into a temporary directory and
+// unsets everything else that decides where goenv keeps its files
+func isolateHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_DATA_HOME", config.RootEnv, config.LayoutEnv, config.SystemRootEnv} {
+		t.Setenv(env, "")
+	}
+	return home
+}
+
+// writeSDK creates a minimal SDK for version in the goenv root
+func writeSDK(t *testing.T, root, version string) {
+	t.Helper()
+	files := map[string]struct {
+		data string
+		mode os.FileMode
+	}{
+		"bin/go":           {"#!/bin/sh\n", 0755},
+		"src/fmt/print.go": {"package fmt\n", 0644},
+		"VERSION":          {version + "\n", 0644},
+	}
+	for name, f := range files {
+		path := filepath.Join(root, "sdk", version, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(f.data), f.mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// writeFile creates path with data, and its parent directories
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// checkLink fails unless path is a symlink to target
+func checkLink(t *testing.T, path, target string) {
+	t.Helper()
+	got, err := os.Readlink(path)
+	if err != nil {
+		t.Errorf("%s: %v", path, err)
+	} else if got != target {
+		t.Errorf("%s links to %s, want %s", path, got, target)
+	}
+}
+
+// checkExists fails unless path exists
+func checkExists(t *testing.T, path string) {
+	t.Helper()
+	if _, err := os.Lstat(path); err != nil {
+		t.Errorf("%s is missing: %v", path, err)
+	}
+}
+
+func TestMigrateLayout(t *testing.T) {
+	home := isolateHome(t)
+	classic := filepath.Join(home, ".goenv")
+
+	// A downloaded SDK, an SDK adopted with --mode link from a directory inside
+	// the tree, and links into the per-version build cache
+	writeSDK(t, classic, "go1.22.5")
+	stash := filepath.Join(classic, "stash", "go1.21.13")
+	writeFile(t, filepath.Join(stash, "bin", "go"), "#!/bin/sh\n")
+	writeFile(t, filepath.Join(stash, "VERSION"), "go1.21.13\n")
+	if err := os.Symlink(stash, filepath.Join(classic, "sdk", "go1.21.13")); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(classic, "go1.22.5", "cache", "README"), "cache\n")
+	writeFile(t, filepath.Join(classic, "go1.22.5", "pkg", "mod", "cache.txt"), "mod\n")
+	if err := os.Symlink(filepath.Join(classic, "go1.22.5", "cache"), filepath.Join(classic, "go1.22.5", "cache-link")); err != nil {
+		t.Fatal(err)
+	}
+	// Relative links move along with the tree and stay as they are
+	if err := os.Symlink("go1.22.5", filepath.Join(classic, "sdk", "latest")); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(classic, config.VersionsFile), "{}\n")
+	writeFile(t, filepath.Join(classic, config.DownloadsDir, "go1.22.5.linux-amd64.tar.gz"), "archive\n")
+	if err := os.MkdirAll(filepath.Join(classic, "bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(config.RootEnv, classic)
+	if err := SaveReceipt(&Receipt{Version: "go1.22.5", Source: SourceDownload}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveReceipt(&Receipt{Version: "go1.21.13", Source: SourceAdopt, Origin: stash, Mode: AdoptLink}); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveSettings(&config.Settings{Cache: config.CacheSettings{ModCache: filepath.Join(classic, config.ModCacheDir)}}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.RootEnv, "")
+
+	if err := MigrateLayout(config.LayoutXDG); err != nil {
+		t.Fatal(err)
+	}
+
+	xdg, err := config.LayoutDirs(config.LayoutXDG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(classic); !os.IsNotExist(err) {
+		t.Errorf("%s still exists after the migration: %v", classic, err)
+	}
+	checkExists(t, filepath.Join(xdg.ConfigDir, config.ConfigFile))
+	checkExists(t, filepath.Join(xdg.CacheDir, config.VersionsFile))
+	checkExists(t, filepath.Join(xdg.CacheDir, config.DownloadsDir, "go1.22.5.linux-amd64.tar.gz"))
+	checkExists(t, filepath.Join(xdg.CacheDir, "go1.22.5", "cache", "README"))
+	checkExists(t, filepath.Join(xdg.DataDir, "go1.22.5", "pkg", "mod", "cache.txt"))
+	checkExists(t, filepath.Join(xdg.DataDir, "sdk", "go1.22.5", "bin", "go"))
+	checkExists(t, filepath.Join(xdg.DataDir, "bin", "go1.22.5"))
+
+	newStash := filepath.Join(xdg.DataDir, "stash", "go1.21.13")
+	checkLink(t, filepath.Join(xdg.DataDir, "sdk", "go1.21.13"), newStash)
+	checkLink(t, filepath.Join(xdg.DataDir, "sdk", "latest"), "go1.22.5")
+	checkLink(t, filepath.Join(xdg.DataDir, "go1.22.5", "cache-link"), filepath.Join(xdg.CacheDir, "go1.22.5", "cache"))
+
+	receipt, err := LoadReceipt("go1.21.13")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt == nil || receipt.Origin != newStash {
+		t.Errorf("receipt of go1.21.13 = %+v, want origin %s", receipt, newStash)
+	}
+	settings, err := config.LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(xdg.CacheDir, config.ModCacheDir); settings.Cache.ModCache != want {
+		t.Errorf("modcache setting = %s, want %s", settings.Cache.ModCache, want)
+	}
+
+	// And back again
+	if err := MigrateLayout(config.LayoutClassic); err != nil {
+		t.Fatal(err)
+	}
+	checkLink(t, filepath.Join(classic, "sdk", "go1.21.13"), stash)
+	checkLink(t, filepath.Join(classic, "go1.22.5", "cache-link"), filepath.Join(classic, "go1.22.5", "cache"))
+	checkExists(t, filepath.Join(classic, "go1.22.5", "cache", "README"))
+	if receipt, err := LoadReceipt("go1.21.13"); err != nil || receipt == nil || receipt.Origin != stash {
+		t.Errorf("receipt of go1.21.13 = %+v (%v), want origin %s", receipt, err, stash)
+	}
+	for _, dir := range uniqueDirs(xdg) {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Errorf("%s still exists after migrating back: %v", dir, err)
+		}
+	}
+}
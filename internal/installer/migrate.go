@@ -0,0 +1,225 @@
+package installer
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+	"github.com/hitzhangjie/goenv/internal/project"
+	"github.com/hitzhangjie/goenv/internal/version"
+)
+
+// Version managers goenv can migrate from
+const (
+	ManagerGVM     = "gvm"
+	ManagerAsdf    = "asdf"
+	ManagerClassic = "goenv-classic" // github.com/syndbg/goenv
+)
+
+// SourceMigrate is recorded in receipts of toolchains imported from another version manager
+const SourceMigrate = "migrate"
+
+// Manager describes where another version manager keeps its toolchains
+type Manager struct {
+	Name string
+	Root string
+}
+
+// NewManager returns the manager with its default root, e.g. ~/.gvm for gvm.
+// An explicit root overrides the default.
+func NewManager(name, root string) (*Manager, error) {
+	if root != "" {
+		if name == ManagerClassic {
+			if err := checkClassicRoot(root); err != nil {
+				return nil, err
+			}
+		}
+		return &Manager{Name: name, Root: root}, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case ManagerGVM:
+		root = os.Getenv("GVM_ROOT")
+		if root == "" {
+			root = filepath.Join(homeDir, ".gvm")
+		}
+	case ManagerAsdf:
+		root = os.Getenv("ASDF_DATA_DIR")
+		if root == "" {
+			root = filepath.Join(homeDir, ".asdf")
+		}
+	case ManagerClassic:
+		// goenv-classic reads GOENV_ROOT too, which now means our root, so only its
+		// default ~/.goenv is assumed, and only if that is not our root as well
+		root = filepath.Join(homeDir, ".goenv")
+		if ours, err := config.GetGoenvRoot(); err == nil && samePath(root, ours) {
+			return nil, fmt.Errorf("%s is goenv's own root, pass --root with the root of %s", root, name)
+		}
+		if err := checkClassicRoot(root); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown version manager %q, want %s, %s or %s", name, ManagerGVM, ManagerAsdf, ManagerClassic)
+	}
+	return &Manager{Name: name, Root: root}, nil
+}
+
+// checkClassicRoot refuses roots without the versions directory of goenv-classic,
+// such as goenv's own root, which would be migrated into itself
+func checkClassicRoot(root string) error {
+	if info, err := os.Stat(filepath.Join(root, "versions")); err != nil || !info.IsDir() {
+		return fmt.Errorf("%s has no versions directory, it is not a %s root", root, ManagerClassic)
+	}
+	return nil
+}
+
+// samePath reports whether a and b name the same directory
+func samePath(a, b string) bool {
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// SDKs returns the toolchains installed by the manager. Entries that do not
+// look like a usable Go SDK are returned as problems.
+func (m *Manager) SDKs() ([]Candidate, []string, error) {
+	var pattern string
+	switch m.Name {
+	case ManagerGVM:
+		pattern = filepath.Join(m.Root, "gos", "*")
+	case ManagerAsdf:
+		pattern = filepath.Join(m.Root, "installs", "golang", "*", "go")
+	case ManagerClassic:
+		pattern = filepath.Join(m.Root, "versions", "*")
+	}
+
+	dirs, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(dirs) == 0 {
+		return nil, nil, fmt.Errorf("no %s toolchains found in %s", m.Name, filepath.Dir(pattern))
+	}
+
+	var candidates []Candidate
+	var problems []string
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(dir, "bin", "go")); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: no bin/go, the toolchain may be incomplete", dir))
+			continue
+		}
+		v, err := ReadGoRootVersion(dir)
+		if err != nil {
+			// Fall back to the directory name, e.g. asdf's installs/golang/1.22.5/go
+			name := filepath.Base(dir)
+			if m.Name == ManagerAsdf {
+				name = filepath.Base(filepath.Dir(dir))
+			}
+			name = version.NormalizeVersion(name)
+			if _, perr := version.ParseVersion(name); perr != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", dir, err))
+				continue
+			}
+			v = name
+		}
+		candidates = append(candidates, Candidate{Version: v, Dir: dir})
+	}
+
+	if m.Name == ManagerGVM {
+		if pkgsets, _ := filepath.Glob(filepath.Join(m.Root, "pkgsets", "*", "*")); len(pkgsets) > 0 {
+			problems = append(problems, fmt.Sprintf("%d gvm pkgset(s) in %s are not migrated, reinstall their packages with the goenv wrappers",
+				len(pkgsets), filepath.Join(m.Root, "pkgsets")))
+		}
+	}
+	return candidates, problems, nil
+}
+
+// Import brings a toolchain of the manager into goenv. mode must not modify
+// the source, so only link, hardlink and copy are accepted.
+func (m *Manager) Import(c Candidate, mode string) error {
+	if mode == AdoptMove {
+		return fmt.Errorf("migrate never modifies %s's files, use link, hardlink or copy", m.Name)
+	}
+	return importSDK(c.Dir, c.Version, mode, SourceMigrate)
+}
+
+// ProjectPin is a per-project version file written for another version manager
+type ProjectPin struct {
+	File    string // e.g. /src/app/.tool-versions
+	Version string // goenv version, e.g. go1.22.5, empty if it cannot be translated
+	Raw     string // version as written in the file
+}
+
+// FindProjectPins walks dir for version files of the manager:
+// .tool-versions for asdf and .go-version for gvm and goenv-classic.
+func (m *Manager) FindProjectPins(dir string) ([]ProjectPin, error) {
+	fileName := ".go-version"
+	if m.Name == ManagerAsdf {
+		fileName = ".tool-versions"
+	}
+
+	var pins []ProjectPin
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable directories
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", "vendor", "node_modules":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != fileName {
+			return nil
+		}
+
+		read := project.ReadVersionFile
+		if m.Name == ManagerAsdf {
+			read = project.ReadToolVersions
+		}
+		raw, err := read(path)
+		if err != nil || raw == "" {
+			return nil
+		}
+		pin := ProjectPin{File: path, Raw: raw}
+		if v := version.NormalizeVersion(raw); isReleaseVersion(v) {
+			pin.Version = v
+		}
+		pins = append(pins, pin)
+		return nil
+	})
+	return pins, err
+}
+
+func isReleaseVersion(v string) bool {
+	_, err := version.ParseVersion(v)
+	return err == nil
+}
+
+// Translate writes a .go-version file with the goenv version next to the pin.
+// Existing files are never overwritten, it returns an empty path if nothing was written.
+func (p ProjectPin) Translate() (string, error) {
+	if p.Version == "" {
+		return "", fmt.Errorf("cannot translate version %q", p.Raw)
+	}
+	target := filepath.Join(filepath.Dir(p.File), ".go-version")
+	if _, err := os.Stat(target); err == nil {
+		return "", nil
+	}
+	if err := os.WriteFile(target, []byte(p.Version+"\n"), 0644); err != nil {
+		return "", err
+	}
+	return target, nil
+}
@@ -0,0 +1,79 @@
+package installer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+// wrapperQuoter quotes values for a wrapper script. In the portable wrapper mode,
+// paths inside the goenv root are written relative to $root, which the prelude
+// derives from the script's own location, so the tree can be moved or copied.
+type wrapperQuoter struct {
+	root   string // goenv root, "" unless portable
+	toRoot string // Path from the directory of the script to the root
+}
+
+// newWrapperQuoter returns the quoter for the wrapper script at scriptPath
+func newWrapperQuoter(scriptPath string) (*wrapperQuoter, error) {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+	if settings.WrapperMode != config.WrapperPortable {
+		return &wrapperQuoter{}, nil
+	}
+
+	root, err := config.GetGoenvRoot()
+	if err != nil {
+		return nil, err
+	}
+	toRoot, err := filepath.Rel(filepath.Dir(scriptPath), root)
+	if err != nil || !isInside(root, scriptPath) {
+		return &wrapperQuoter{}, nil
+	}
+	return &wrapperQuoter{root: root, toRoot: toRoot}, nil
+}
+
+// prelude returns the lines setting $root, if the script needs them. Symlinks to
+// the script, e.g. ~/bin/go -> ~/.goenv/bin/go1.22.5, are followed first.
+func (q *wrapperQuoter) prelude() string {
+	if q.root == "" {
+		return ""
+	}
+	return `self="$0"
+while [ -L "$self" ]; do
+  link="$(readlink -- "$self")"
+  case "$link" in
+    /*) self="$link" ;;
+    *) self="$(dirname -- "$self")/$link" ;;
+  esac
+done
+` + fmt.Sprintf("root=\"$(CDPATH= cd -- \"$(dirname -- \"$self\")\"/%s && pwd)\"\n", shellQuote(q.toRoot))
+}
+
+// quote quotes value as a single shell word
+func (q *wrapperQuoter) quote(value string) string {
+	if q.root == "" || !isInside(q.root, value) {
+		return shellQuote(value)
+	}
+	rel, _ := filepath.Rel(q.root, value)
+	return `"$root"` + shellQuote("/"+rel)
+}
+
+// assignment returns the shell assignment of e. A GOTOOLCHAIN exported by the
+// user takes precedence over the one of the wrapper.
+func (q *wrapperQuoter) assignment(e EnvVar) string {
+	if e.Name == "GOTOOLCHAIN" {
+		return e.Name + "=${GOTOOLCHAIN:-" + q.quote(e.Value) + "}"
+	}
+	return e.Name + "=" + q.quote(e.Value)
+}
+
+// isInside reports whether path is within dir
+func isInside(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
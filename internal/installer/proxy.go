@@ -0,0 +1,287 @@
+package installer
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+	"github.com/hitzhangjie/goenv/internal/system"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+const (
+	ToolchainModule = "golang.org/toolchain" // Module the go command downloads toolchains from
+	DefaultProxy    = "https://proxy.golang.org"
+)
+
+// ProxyOptions controls where a toolchain module is fetched from and how it is verified
+type ProxyOptions struct {
+	Proxy string // GOPROXY base URL, https:// or file://
+	Sum   string // Expected hash ("h1:...") or go.sum line, takes precedence over SumDB
+	SumDB string // GOSUMDB setting, "off" disables verification against the checksum database
+}
+
+// DefaultProxyOptions derives proxy options from the GOPROXY and GOSUMDB environment variables
+func DefaultProxyOptions() ProxyOptions {
+	opts := ProxyOptions{Proxy: DefaultProxy, SumDB: DefaultSumDB}
+	for _, p := range strings.FieldsFunc(os.Getenv("GOPROXY"), func(r rune) bool { return r == ',' || r == '|' }) {
+		if p != "direct" && p != "off" {
+			opts.Proxy = p
+			break
+		}
+	}
+	if sumdb := os.Getenv("GOSUMDB"); sumdb != "" {
+		opts.SumDB = sumdb
+	}
+	return opts
+}
+
+// InstallFromProxy installs a Go version from the golang.org/toolchain module
+// served by a GOPROXY, the same archives the go command uses for GOTOOLCHAIN switching.
+func InstallFromProxy(version string, opts ProxyOptions) error {
+	goos, err := system.GetGOOS()
+	if err != nil {
+		return fmt.Errorf("failed to get GOOS: %w", err)
+	}
+	goarch, err := system.GetGOARCH()
+	if err != nil {
+		return fmt.Errorf("failed to get GOARCH: %w", err)
+	}
+
+	modVersion := fmt.Sprintf("v0.0.1-%s.%s-%s", version, goos, goarch)
+	escPath, err := module.EscapePath(ToolchainModule)
+	if err != nil {
+		return err
+	}
+	escVersion, err := module.EscapeVersion(modVersion)
+	if err != nil {
+		return err
+	}
+	zipURL := fmt.Sprintf("%s/%s/@v/%s.zip", strings.TrimSuffix(opts.Proxy, "/"), escPath, escVersion)
+	fmt.Printf("Installing %s for %s/%s from %s...\n", version, goos, goarch, opts.Proxy)
+
+	downloadsDir, err := config.GetDownloadsDir()
+	if err != nil {
+		return err
+	}
+	if err := config.EnsureDir(downloadsDir); err != nil {
+		return fmt.Errorf("failed to create downloads directory: %w", err)
+	}
+
+	sdkDir, err := config.GetSDKDir()
+	if err != nil {
+		return err
+	}
+	if err := config.EnsureDir(sdkDir); err != nil {
+		return fmt.Errorf("failed to create SDK directory: %w", err)
+	}
+
+	binDir, err := config.GetBinDir()
+	if err != nil {
+		return err
+	}
+	if err := config.EnsureDir(binDir); err != nil {
+		return fmt.Errorf("failed to create bin directory: %w", err)
+	}
+
+	installDir := filepath.Join(sdkDir, version)
+	if _, err := os.Stat(filepath.Join(installDir, "bin", "go")); err == nil {
+		fmt.Printf("%s is already installed.\n", version)
+		return nil
+	}
+
+	zipPath := filepath.Join(downloadsDir, "toolchain-"+modVersion+".zip")
+	if _, err := os.Stat(zipPath); err == nil {
+		fmt.Printf("Found existing download: %s, skipping download.\n", zipPath)
+	} else if err := fetchProxyFile(zipURL, zipPath); err != nil {
+		return fmt.Errorf("failed to download: %w", err)
+	}
+
+	hash, err := verifyToolchainZip(zipPath, modVersion, opts)
+	if err != nil {
+		// Never keep an archive that failed verification around for the next attempt
+		os.Remove(zipPath)
+		return err
+	}
+
+	prefix := ToolchainModule + "@" + modVersion + "/"
+	if err := extractZip(zipPath, prefix, installDir); err != nil {
+		os.RemoveAll(installDir)
+		return fmt.Errorf("failed to extract: %w", err)
+	}
+
+	if err := createGoScript(version, installDir, binDir); err != nil {
+		return fmt.Errorf("failed to create go script: %w", err)
+	}
+	if err := createGofmtScript(version, installDir, binDir); err != nil {
+		return fmt.Errorf("failed to create gofmt script: %w", err)
+	}
+
+	receipt := &Receipt{
+		Version:     version,
+		Source:      SourceProxy,
+		URL:         zipURL,
+		Hash:        hash,
+		InstalledAt: time.Now(),
+	}
+	if err := SaveReceipt(receipt); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully installed %s\n", version)
+	fmt.Printf("Use '%s' to run this version of Go\n", version)
+	afterInstall(version)
+	return nil
+}
+
+// fetchProxyFile downloads rawURL to dest, file:// URLs are copied from the local proxy directory
+func fetchProxyFile(rawURL, dest string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "file" {
+		return downloadFile(rawURL, dest)
+	}
+
+	src, err := os.Open(filepath.FromSlash(u.Path))
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	part := dest + PartSuffix
+	out, err := os.Create(part)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		os.Remove(part)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(part)
+		return err
+	}
+	return os.Rename(part, dest)
+}
+
+// verifyToolchainZip checks the module hash of the zip and returns it
+func verifyToolchainZip(zipPath, modVersion string, opts ProxyOptions) (string, error) {
+	hash, err := dirhash.HashZip(zipPath, dirhash.Hash1)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", zipPath, err)
+	}
+
+	var want, from string
+	switch {
+	case opts.Sum != "":
+		want, err = parseSum(opts.Sum, modVersion)
+		if err != nil {
+			return "", err
+		}
+		from = "the given go.sum entry"
+	case opts.SumDB != "off":
+		fmt.Println("Verifying against the checksum database...")
+		want, err = lookupSum(opts.SumDB, opts.Proxy, ToolchainModule, modVersion)
+		if err != nil && strings.HasPrefix(opts.Proxy, "file://") {
+			// The checksum database is online even if the proxy is not
+			return "", fmt.Errorf("failed to verify %s@%s: %w (offline installs from a file:// proxy need --sum or GOSUMDB=off)", ToolchainModule, modVersion, err)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to verify %s@%s: %w", ToolchainModule, modVersion, err)
+		}
+		from = "the checksum database"
+	default:
+		fmt.Fprintf(os.Stderr, "Warning: GOSUMDB=off, %s@%s is not verified.\n", ToolchainModule, modVersion)
+		return hash, nil
+	}
+
+	if hash != want {
+		return "", fmt.Errorf("SECURITY ERROR: %s@%s has hash %s, but %s says %s", ToolchainModule, modVersion, hash, from, want)
+	}
+	fmt.Printf("Verified %s (%s)\n", hash, from)
+	return hash, nil
+}
+
+// parseSum extracts the hash from either a bare "h1:..." value or a go.sum line
+func parseSum(sum, modVersion string) (string, error) {
+	fields := strings.Fields(sum)
+	switch len(fields) {
+	case 1:
+		return fields[0], nil
+	case 3:
+		if fields[0] != ToolchainModule || fields[1] != modVersion {
+			return "", fmt.Errorf("go.sum line is for %s@%s, want %s@%s", fields[0], fields[1], ToolchainModule, modVersion)
+		}
+		return fields[2], nil
+	}
+	return "", fmt.Errorf("invalid checksum %q: want h1:... or a go.sum line", sum)
+}
+
+// extractZip unpacks the files under prefix into destDir.
+// Module zips carry no file modes, so like the go command we mark binaries
+// under bin/ and pkg/tool/ executable.
+func extractZip(zipPath, prefix, destDir string) error {
+	fmt.Printf("Extracting to %s...\n", destDir)
+
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		name, ok := strings.CutPrefix(f.Name, prefix)
+		if !ok {
+			return fmt.Errorf("unexpected file %s in toolchain zip", f.Name)
+		}
+		if name == "" || strings.HasSuffix(name, "/") {
+			continue
+		}
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("invalid file name %s in toolchain zip", f.Name)
+		}
+
+		target := filepath.Join(destDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		mode := os.FileMode(0644)
+		if strings.HasPrefix(name, "bin/") || strings.HasPrefix(name, "pkg/tool/") {
+			mode = 0755
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		// Existing files may be hardlinks shared with other SDKs, replace them
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			rc.Close()
+			return err
+		}
+		outFile, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+		if err != nil {
+			rc.Close()
+			return err
+		}
+		_, err = io.Copy(outFile, rc)
+		rc.Close()
+		outFile.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
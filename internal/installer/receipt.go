@@ -0,0 +1,117 @@
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+// Install sources recorded in receipts
+const (
+	SourceDownload = "download"
+	SourceGit      = "git"
+	SourceVariant  = "source"
+	SourceProxy    = "proxy"
+	SourceAdopt    = "adopt"
+)
+
+// Receipt records how an installed Go version was obtained
+type Receipt struct {
+	Version     string    `json:"version"`
+	Source      string    `json:"source"`
+	URL         string    `json:"url,omitempty"`
+	Hash        string    `json:"hash,omitempty"`
+	GitRepo     string    `json:"git_repo,omitempty"`
+	GitRef      string    `json:"git_ref,omitempty"`
+	GitCommit   string    `json:"git_commit,omitempty"`
+	Bootstrap   string    `json:"bootstrap,omitempty"`
+	BaseVersion string    `json:"base_version,omitempty"`
+	Experiments []string  `json:"experiments,omitempty"`
+	Patches     []Patch   `json:"patches,omitempty"`
+	Origin      string    `json:"origin,omitempty"`
+	Mode        string    `json:"mode,omitempty"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// Patch identifies a patch applied to a variant build
+type Patch struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+}
+
+func receiptPath(version string) (string, error) {
+	dir, err := config.GetReceiptsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, version+".json"), nil
+}
+
+// LoadReceipt loads the receipt of an installed version, falling back to the
+// system store for versions the user did not install.
+// It returns nil if the version has no receipt, e.g. it was installed by an older goenv.
+func LoadReceipt(version string) (*Receipt, error) {
+	path, err := receiptPath(version)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !installedByUser(version) {
+		if systemRoot, _ := config.GetSystemRoot(); systemRoot != "" {
+			path = filepath.Join(systemRoot, config.ReceiptsDir, version+".json")
+			data, err = os.ReadFile(path)
+		}
+	}
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read receipt: %w", err)
+	}
+
+	var receipt Receipt
+	if err := json.Unmarshal(data, &receipt); err != nil {
+		return nil, fmt.Errorf("failed to parse receipt %s: %w", path, err)
+	}
+	return &receipt, nil
+}
+
+// installedByUser reports whether version is in the user's own SDK directory
+func installedByUser(version string) bool {
+	sdkDir, err := config.GetSDKDir()
+	if err != nil {
+		return false
+	}
+	_, err = os.Lstat(filepath.Join(sdkDir, version))
+	return err == nil
+}
+
+// SaveReceipt writes the receipt of an installed version
+func SaveReceipt(receipt *Receipt) error {
+	dir, err := config.GetReceiptsDir()
+	if err != nil {
+		return err
+	}
+	if err := config.EnsureDir(dir); err != nil {
+		return fmt.Errorf("failed to create receipts directory: %w", err)
+	}
+
+	path, err := receiptPath(receipt.Version)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(receipt, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal receipt: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write receipt: %w", err)
+	}
+	return nil
+}
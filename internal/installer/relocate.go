@@ -0,0 +1,108 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+// Relocate moves the goenv root to newRoot, repoints symlinks, receipts and cache
+// settings that refer to the old location and regenerates the wrappers.
+// It sets GOENV_ROOT for the rest of the process, the caller has to tell the user
+// to do the same.
+func Relocate(newRoot string) error {
+	layout, err := config.GetLayout()
+	if err != nil {
+		return err
+	}
+	if layout.Name != config.LayoutClassic {
+		return fmt.Errorf("relocate needs the %s layout, the %s layout spreads goenv over several directories (see goenv migrate-layout)", config.LayoutClassic, layout.Name)
+	}
+	oldRoot := layout.DataDir
+	if _, err := os.Stat(oldRoot); os.IsNotExist(err) {
+		return fmt.Errorf("nothing to relocate, %s does not exist", oldRoot)
+	}
+
+	newRoot, err = filepath.Abs(newRoot)
+	if err != nil {
+		return err
+	}
+	if isInside(oldRoot, newRoot) {
+		return fmt.Errorf("cannot relocate %s into itself", oldRoot)
+	}
+	if entries, err := os.ReadDir(newRoot); err == nil {
+		if len(entries) > 0 {
+			return fmt.Errorf("%s already exists and is not empty", newRoot)
+		}
+		if err := os.Remove(newRoot); err != nil {
+			return err
+		}
+	}
+	if err := config.EnsureDir(filepath.Dir(newRoot)); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	fmt.Printf("Moving %s to %s...\n", oldRoot, newRoot)
+	if err := moveTree(oldRoot, newRoot); err != nil {
+		return fmt.Errorf("failed to move goenv root: %w", err)
+	}
+	os.Setenv(config.RootEnv, newRoot)
+
+	rebase := func(path string) (string, bool) {
+		if !filepath.IsAbs(path) || !isInside(oldRoot, path) {
+			return path, false
+		}
+		rel, _ := filepath.Rel(oldRoot, path)
+		return filepath.Join(newRoot, rel), true
+	}
+
+	if err := rebaseSymlinks([]string{newRoot}, rebase); err != nil {
+		return err
+	}
+
+	if err := rebaseReceipts(rebase); err != nil {
+		return err
+	}
+
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return err
+	}
+	modCache, movedMod := rebase(settings.Cache.ModCache)
+	goCache, movedGo := rebase(settings.Cache.GoCache)
+	if movedMod || movedGo {
+		settings.Cache.ModCache, settings.Cache.GoCache = modCache, goCache
+		if err := config.SaveSettings(settings); err != nil {
+			return err
+		}
+	}
+
+	return FixScripts()
+}
+
+// rebaseReceipts updates the origins and git checkouts recorded in receipts that
+// rebase maps elsewhere
+func rebaseReceipts(rebase func(path string) (string, bool)) error {
+	installed, err := ListInstalled()
+	if err != nil {
+		return err
+	}
+	for _, inst := range installed {
+		receipt, err := LoadReceipt(inst.Version)
+		if err != nil || receipt == nil {
+			continue
+		}
+		origin, movedOrigin := rebase(receipt.Origin)
+		repo, movedRepo := rebase(receipt.GitRepo)
+		if !movedOrigin && !movedRepo {
+			continue
+		}
+		receipt.Origin, receipt.GitRepo = origin, repo
+		if err := SaveReceipt(receipt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+func TestRelocate(t *testing.T) {
+	isolateHome(t)
+	base := t.TempDir()
+	oldRoot := filepath.Join(base, "old")
+	newRoot := filepath.Join(base, "moved", "goenv")
+	t.Setenv(config.RootEnv, oldRoot)
+
+	writeSDK(t, oldRoot, "go1.22.5")
+	stash := filepath.Join(oldRoot, "stash", "go1.21.13")
+	writeFile(t, filepath.Join(stash, "bin", "go"), "#!/bin/sh\n")
+	if err := os.Symlink(stash, filepath.Join(oldRoot, "sdk", "go1.21.13")); err != nil {
+		t.Fatal(err)
+	}
+	// Links out of the tree stay as they are
+	outside := filepath.Join(base, "elsewhere")
+	if err := os.Symlink(outside, filepath.Join(oldRoot, "outside")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(oldRoot, "bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveReceipt(&Receipt{Version: "go1.21.13", Source: SourceAdopt, Origin: stash, Mode: AdoptLink}); err != nil {
+		t.Fatal(err)
+	}
+	checkout := filepath.Join(oldRoot, "src", "go")
+	if err := SaveReceipt(&Receipt{Version: "go1.22.5", Source: SourceDownload, GitRepo: checkout}); err != nil {
+		t.Fatal(err)
+	}
+	settings := &config.Settings{Cache: config.CacheSettings{
+		ModCache: filepath.Join(oldRoot, config.ModCacheDir),
+		GoCache:  filepath.Join(base, "gocache"),
+	}}
+	if err := config.SaveSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, target := range []string{oldRoot, filepath.Join(oldRoot, "sub")} {
+		if err := Relocate(target); err == nil {
+			t.Errorf("Relocate(%s) into the root itself succeeded", target)
+		}
+	}
+	writeFile(t, filepath.Join(base, "full", "file"), "x\n")
+	if err := Relocate(filepath.Join(base, "full")); err == nil {
+		t.Error("Relocate into a non-empty directory succeeded")
+	}
+
+	if err := Relocate(newRoot); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv(config.RootEnv); got != newRoot {
+		t.Errorf("%s = %s after Relocate, want %s", config.RootEnv, got, newRoot)
+	}
+	if _, err := os.Stat(oldRoot); !os.IsNotExist(err) {
+		t.Errorf("%s still exists after Relocate: %v", oldRoot, err)
+	}
+	newStash := filepath.Join(newRoot, "stash", "go1.21.13")
+	checkLink(t, filepath.Join(newRoot, "sdk", "go1.21.13"), newStash)
+	checkLink(t, filepath.Join(newRoot, "outside"), outside)
+
+	if receipt, err := LoadReceipt("go1.21.13"); err != nil || receipt == nil || receipt.Origin != newStash {
+		t.Errorf("receipt of go1.21.13 = %+v (%v), want origin %s", receipt, err, newStash)
+	}
+	newCheckout := filepath.Join(newRoot, "src", "go")
+	if receipt, err := LoadReceipt("go1.22.5"); err != nil || receipt == nil || receipt.GitRepo != newCheckout {
+		t.Errorf("receipt of go1.22.5 = %+v (%v), want git repo %s", receipt, err, newCheckout)
+	}
+
+	settings, err := config.LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(newRoot, config.ModCacheDir); settings.Cache.ModCache != want {
+		t.Errorf("modcache setting = %s, want %s", settings.Cache.ModCache, want)
+	}
+	if want := filepath.Join(base, "gocache"); settings.Cache.GoCache != want {
+		t.Errorf("gocache setting = %s, want it unchanged at %s", settings.Cache.GoCache, want)
+	}
+
+	wrapper, err := os.ReadFile(filepath.Join(newRoot, "bin", "go1.22.5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(wrapper), oldRoot) || !strings.Contains(string(wrapper), newRoot) {
+		t.Errorf("wrapper was not regenerated for %s:\n%s", newRoot, wrapper)
+	}
+}
+
+func TestRelocateNeedsClassicLayout(t *testing.T) {
+	isolateHome(t)
+	t.Setenv(config.LayoutEnv, config.LayoutXDG)
+	if err := Relocate(t.TempDir()); err == nil {
+		t.Error("Relocate succeeded in the xdg layout")
+	}
+}
@@ -0,0 +1,120 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+// ShareCaches merges the per-version module caches into modCache, then makes all
+// versions use it as GOMODCACHE, and goCache as GOCACHE unless it is empty, and
+// regenerates the wrappers. The settings only change once the merge succeeded, a
+// failed merge leaves the wrappers on the per-version caches.
+func ShareCaches(modCache, goCache string) error {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return err
+	}
+	root, err := config.GetGoenvRoot()
+	if err != nil {
+		return err
+	}
+	installed, err := ListInstalled()
+	if err != nil {
+		return err
+	}
+
+	var toMerge []Installation
+	for _, inst := range installed {
+		src := filepath.Join(root, inst.Version, "pkg", "mod")
+		if _, err := os.Stat(src); err != nil {
+			continue
+		}
+		if src == modCache {
+			return fmt.Errorf("%s is the module cache of %s, choose another directory", modCache, inst.Version)
+		}
+		toMerge = append(toMerge, inst)
+	}
+
+	for _, inst := range toMerge {
+		src := filepath.Join(root, inst.Version, "pkg", "mod")
+		moved, err := mergeTree(src, modCache)
+		if err != nil {
+			return fmt.Errorf("failed to merge the module cache of %s: %w", inst.Version, err)
+		}
+		// What is left is already in the shared cache
+		if err := removeTree(src); err != nil {
+			return fmt.Errorf("failed to remove the module cache of %s: %w", inst.Version, err)
+		}
+		fmt.Printf("Merged module cache of %s (%d entries moved)\n", inst.Version, moved)
+	}
+
+	settings.Cache.ModCache = modCache
+	if goCache != "" {
+		settings.Cache.GoCache = goCache
+	}
+	if err := config.SaveSettings(settings); err != nil {
+		return err
+	}
+	return FixScripts()
+}
+
+// mergeTree moves the entries of src missing from dst into dst and returns how many were moved.
+// Read-only directories in dst are extracted modules, complete by construction,
+// so they are not descended into.
+func mergeTree(src, dst string) (int, error) {
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return 0, err
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, entry := range entries {
+		from, to := filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())
+
+		info, err := os.Lstat(to)
+		if os.IsNotExist(err) {
+			if err := moveEntry(from, to); err != nil {
+				return moved, err
+			}
+			moved++
+			continue
+		}
+		if err != nil {
+			return moved, err
+		}
+
+		if entry.IsDir() && info.IsDir() && info.Mode().Perm()&0200 != 0 {
+			n, err := mergeTree(from, to)
+			moved += n
+			if err != nil {
+				return moved, err
+			}
+		}
+	}
+	return moved, nil
+}
+
+// moveEntry moves a file or directory, keeping read-only directories read-only
+func moveEntry(from, to string) error {
+	info, err := os.Lstat(from)
+	if err != nil {
+		return err
+	}
+	// Moving a directory updates its .. entry, which needs write permission
+	if !info.IsDir() || info.Mode().Perm()&0200 != 0 {
+		return moveTree(from, to)
+	}
+	if err := os.Chmod(from, info.Mode().Perm()|0200); err != nil {
+		return err
+	}
+	if err := moveTree(from, to); err != nil {
+		return err
+	}
+	return os.Chmod(to, info.Mode().Perm())
+}
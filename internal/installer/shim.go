@@ -0,0 +1,191 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+// IsShim reports whether goenv was invoked through a wrapper link, e.g. as go1.22.5,
+// gofmt1.22.5, the plain go and gofmt set by goenv use, or a tool shim like gopls@go1.22.5
+func IsShim(arg0 string) bool {
+	name := strings.TrimSuffix(filepath.Base(arg0), ".exe")
+	if name == "goenv" {
+		return false
+	}
+	if _, _, ok := parseWrapperName(name); ok {
+		return true
+	}
+	// Only names with a separator can follow the tool shim pattern, others are
+	// told apart without loading the configuration
+	if !config.HasSeparator(name) {
+		return false
+	}
+	_, _, ok := findToolShim(name)
+	return ok
+}
+
+// parseWrapperName splits the name of a go or gofmt wrapper into the tool and
+// the version it runs, which is "" for the plain go and gofmt
+func parseWrapperName(name string) (tool, version string, ok bool) {
+	switch name {
+	case "go", "gofmt":
+		return name, "", true
+	}
+	tool, version = "go", name
+	if rest, found := strings.CutPrefix(name, "gofmt-"); found {
+		// Only development builds, see gofmtWrapperName
+		if !strings.HasPrefix(rest, DevVersionPrefix) {
+			return "", "", false
+		}
+		tool, version = "gofmt", rest
+	} else if rest, found := strings.CutPrefix(name, "gofmt"); found {
+		tool, version = "gofmt", "go"+rest
+		if strings.HasPrefix(version, DevVersionPrefix) {
+			return "", "", false
+		}
+	}
+	if !strings.HasPrefix(version, "go") {
+		return "", "", false
+	}
+	if !isVersionName(version) {
+		return "", "", false
+	}
+	return tool, version, true
+}
+
+// findToolShim splits name if it follows the configured tool shim pattern
+func findToolShim(name string) (tool, version string, ok bool) {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return "", "", false
+	}
+	return parseToolShim(settings.ToolShimPattern, name)
+}
+
+// RunShim replaces the process with the go or gofmt binary the wrapper name
+// stands for, with the same environment the wrapper scripts set
+func RunShim(args []string) error {
+	name := strings.TrimSuffix(filepath.Base(args[0]), ".exe")
+	tool, version, ok := parseWrapperName(name)
+	if !ok {
+		if tool, version, ok := findToolShim(name); ok {
+			return runToolShim(tool, version, args)
+		}
+		return fmt.Errorf("%s is not a goenv wrapper", name)
+	}
+
+	if version == "" {
+		v, err := GetDefault()
+		if err != nil {
+			return err
+		}
+		if v == "" {
+			return fmt.Errorf("no default version set, run 'goenv use <version>'")
+		}
+		version = v
+	}
+
+	inst, err := FindInstalled(version)
+	if err != nil {
+		return err
+	}
+
+	overrides := make(map[string]string)
+	if tool == "go" {
+		env, err := WrapperEnv(inst.Version, inst.Dir)
+		if err != nil {
+			return err
+		}
+		addWrapperEnv(overrides, env)
+	}
+	environ := mergeEnv(os.Environ(), overrides)
+	if tool == "go" {
+		if dir, err := os.Getwd(); err == nil {
+			if mismatch := FindToolchainMismatch(inst, dir, environ); mismatch != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", mismatch)
+			}
+		}
+	}
+
+	bin := filepath.Join(inst.Dir, "bin", tool)
+	argv := append([]string{bin}, args[1:]...)
+	if err := syscall.Exec(bin, argv, environ); err != nil {
+		return fmt.Errorf("failed to run %s: %w", bin, err)
+	}
+	return nil
+}
+
+// runToolShim replaces the process with tool from the GOBIN of version, with the
+// environment the tool shim scripts set
+func runToolShim(tool, version string, args []string) error {
+	inst, err := FindInstalled(version)
+	if err != nil {
+		return err
+	}
+	env, err := WrapperEnv(inst.Version, inst.Dir)
+	if err != nil {
+		return err
+	}
+	shimDir, err := EnsureShimDir(inst)
+	if err != nil {
+		return err
+	}
+
+	overrides := map[string]string{
+		"PATH": shimDir + string(os.PathListSeparator) + os.Getenv("PATH"),
+	}
+	var bin string
+	addWrapperEnv(overrides, env)
+	for _, e := range env {
+		if e.Name == "GOBIN" {
+			bin = filepath.Join(e.Value, tool)
+		}
+	}
+
+	argv := append([]string{bin}, args[1:]...)
+	if err := syscall.Exec(bin, argv, mergeEnv(os.Environ(), overrides)); err != nil {
+		return fmt.Errorf("failed to run %s: %w", bin, err)
+	}
+	return nil
+}
+
+// shimTarget returns the goenv binary wrapper links point to. Binaries built by
+// go run or go test live in a temporary directory and are refused, links to them
+// would break as soon as the command exits.
+func shimTarget() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	exe, err = filepath.EvalSymlinks(exe)
+	if err != nil {
+		return "", err
+	}
+
+	dirs := []string{os.TempDir()}
+	if tmp, err := filepath.EvalSymlinks(os.TempDir()); err == nil {
+		dirs = append(dirs, tmp)
+	}
+	if cache, err := os.UserCacheDir(); err == nil {
+		dirs = append(dirs, filepath.Join(cache, "go-build"))
+	}
+	if cache := os.Getenv("GOCACHE"); cache != "" {
+		dirs = append(dirs, cache)
+	}
+	for _, dir := range dirs {
+		if isInside(dir, exe) {
+			return "", fmt.Errorf("goenv runs from %s, a temporary build", exe)
+		}
+	}
+	for _, elem := range strings.Split(filepath.ToSlash(exe), "/") {
+		if strings.HasPrefix(elem, "go-build") {
+			return "", fmt.Errorf("goenv runs from %s, a temporary build", exe)
+		}
+	}
+	return exe, nil
+}
@@ -0,0 +1,46 @@
+package installer
+
+import "testing"
+
+func TestParseWrapperName(t *testing.T) {
+	tests := []struct {
+		name    string
+		tool    string
+		version string
+		ok      bool
+	}{
+		{"go", "go", "", true},
+		{"gofmt", "gofmt", "", true},
+		{"go1.22.5", "go", "go1.22.5", true},
+		{"gofmt1.22.5", "gofmt", "go1.22.5", true},
+		{"go1.23rc1", "go", "go1.23rc1", true},
+		{"go1.22beta1", "go", "go1.22beta1", true},
+		{"go1.22.5+boring", "go", "go1.22.5+boring", true},
+		{"gofmt1.22.5+boring", "gofmt", "go1.22.5+boring", true},
+		{"godev-f52d441ca8", "go", "godev-f52d441ca8", true},
+		{"gofmt-godev-f52d441ca8", "gofmt", "godev-f52d441ca8", true},
+		{"gofmtdev-f52d441ca8", "", "", false},
+		{"gofmt-go1.22.5", "", "", false},
+		{"godev-nothex", "", "", false},
+		{"goenv", "", "", false},
+		{"gopls", "", "", false},
+		{"gopls@go1.22.5", "", "", false},
+	}
+	for _, tt := range tests {
+		tool, version, ok := parseWrapperName(tt.name)
+		if tool != tt.tool || version != tt.version || ok != tt.ok {
+			t.Errorf("parseWrapperName(%q) = %q, %q, %v, want %q, %q, %v",
+				tt.name, tool, version, ok, tt.tool, tt.version, tt.ok)
+		}
+	}
+}
+
+func TestGofmtWrapperNameRoundTrip(t *testing.T) {
+	for _, version := range []string{"go1.22.5", "go1.23rc1", "go1.22.5+boring", "godev-f52d441ca8"} {
+		name := gofmtWrapperName(version)
+		tool, got, ok := parseWrapperName(name)
+		if tool != "gofmt" || got != version || !ok {
+			t.Errorf("parseWrapperName(%q) = %q, %q, %v, want gofmt, %q, true", name, tool, got, ok, version)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+// EnsureShimDir returns a directory in which plain go and gofmt run inst's wrappers,
+// creating it if needed. Shell hooks put this directory on PATH.
+func EnsureShimDir(inst *Installation) (string, error) {
+	shimsDir, err := config.GetShimsDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(shimsDir, inst.Version)
+	if _, err := os.Stat(filepath.Join(dir, "gofmt")); err == nil {
+		return dir, nil
+	}
+	if err := writeShimDir(dir, inst.Version); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// writeShimDir writes go and gofmt in dir, forwarding to the versioned wrappers.
+// These are plain sh scripts so they work whatever form the wrappers take.
+func writeShimDir(dir, version string) error {
+	binDir, err := config.GetBinDir()
+	if err != nil {
+		return err
+	}
+	if err := config.EnsureDir(dir); err != nil {
+		return fmt.Errorf("failed to create shim directory: %w", err)
+	}
+
+	targets := map[string]string{
+		"go":    filepath.Join(binDir, version),
+		"gofmt": filepath.Join(binDir, gofmtWrapperName(version)),
+	}
+	for name, target := range targets {
+		path := filepath.Join(dir, name)
+		q, err := newWrapperQuoter(path)
+		if err != nil {
+			return err
+		}
+		script := fmt.Sprintf("#!/bin/sh\n%sexec %s \"$@\"\n", q.prelude(), q.quote(target))
+		if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fixShimDirs regenerates existing shim directories and removes those of versions
+// that are no longer installed
+func fixShimDirs() error {
+	shimsDir, err := config.GetShimsDir()
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(shimsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read shims directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		dir := filepath.Join(shimsDir, entry.Name())
+		if _, err := FindInstalled(entry.Name()); err != nil {
+			if err := os.RemoveAll(dir); err != nil {
+				return fmt.Errorf("failed to remove stale shims %s: %w", dir, err)
+			}
+			continue
+		}
+		if err := writeShimDir(dir, entry.Name()); err != nil {
+			return fmt.Errorf("failed to fix shims for %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
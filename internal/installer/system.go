@@ -0,0 +1,154 @@
+package installer
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+// systemStoreDirs are the directories of the system store that members of its group write to
+var systemStoreDirs = []string{"", config.SDKDir, config.ReceiptsDir, config.DownloadsDir, config.BuildDir, config.SumDBDir}
+
+// installingToStore is set by UseSystemStore. Wrappers and tools hold per-user
+// paths, so they are left to each user rather than written into the store.
+var installingToStore bool
+
+// userRoot is the GOENV_ROOT UseSystemStore replaced, restored by LeaveSystemStore
+var userRoot *string
+
+// UseSystemStore makes goenv install into the shared system store for the rest of the
+// process, $GOENV_SYSTEM_ROOT or /opt/goenv, and returns its path. The directories
+// of the store are group-writable and setgid, so members of the store's group can
+// install and installs keep the group, and sticky, so members cannot remove or
+// replace what others installed. Files are only writable by whoever installed them.
+func UseSystemStore() (string, error) {
+	root := os.Getenv(config.SystemRootEnv)
+	if root == "" {
+		root = config.DefaultSystemRoot
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+
+	setStoreUmask()
+	for _, name := range systemStoreDirs {
+		dir := filepath.Join(root, name)
+		if err := os.MkdirAll(dir, 0775); err != nil {
+			return "", fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+		// Only the owner can change the mode, directories created by another admin are left alone
+		os.Chmod(dir, 0775|os.ModeSetgid|os.ModeSticky)
+	}
+	if previous, ok := os.LookupEnv(config.RootEnv); ok {
+		userRoot = &previous
+	}
+	installingToStore = true
+	os.Setenv(config.RootEnv, root)
+	return root, nil
+}
+
+// LeaveSystemStore switches back to the user's goenv after UseSystemStore and
+// creates the user's wrappers for the versions now in the store
+func LeaveSystemStore() error {
+	if userRoot != nil {
+		os.Setenv(config.RootEnv, *userRoot)
+	} else {
+		os.Unsetenv(config.RootEnv)
+	}
+	installingToStore = false
+	_, err := addSystemWrappers()
+	return err
+}
+
+// SealSystemStore makes the files of the SDKs in the system store read-only and
+// takes away group write access from SDKs and receipts, so that members of the
+// store's group cannot modify them. Directories stay writable by their owner, who
+// can still replace or remove an SDK. Files owned by other users and adopted SDKs
+// linked into the store are skipped.
+func SealSystemStore() error {
+	sdkDir, err := config.GetSDKDir()
+	if err != nil {
+		return err
+	}
+	receiptsDir, err := config.GetReceiptsDir()
+	if err != nil {
+		return err
+	}
+	installed, err := listSDKs(sdkDir, true)
+	if err != nil {
+		return err
+	}
+
+	dirs := []string{receiptsDir}
+	for _, inst := range installed {
+		dirs = append(dirs, inst.Dir)
+	}
+	for _, dir := range dirs {
+		info, err := os.Lstat(dir)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.Type()&fs.ModeSymlink != 0 {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if !ownedByUs(info) {
+				return nil
+			}
+			if path == receiptsDir {
+				// A store directory, members of the group add receipts to it
+				return nil
+			}
+			if d.IsDir() || dir == receiptsDir {
+				return os.Chmod(path, info.Mode()&^0022)
+			}
+			return os.Chmod(path, info.Mode().Perm()&^0222)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to seal %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// addSystemWrappers creates the go and gofmt wrappers of versions in the system
+// store that have none yet and returns the versions it added
+func addSystemWrappers() ([]string, error) {
+	installed, err := ListInstalled()
+	if err != nil {
+		return nil, err
+	}
+	binDir, err := config.GetBinDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var added []string
+	for _, inst := range installed {
+		if !inst.System {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(binDir, inst.Version)); err == nil {
+			continue
+		}
+		if err := config.EnsureDir(binDir); err != nil {
+			return added, fmt.Errorf("failed to create bin directory: %w", err)
+		}
+		if err := createGoScript(inst.Version, inst.Dir, binDir); err != nil {
+			return added, fmt.Errorf("failed to create go script for %s: %w", inst.Version, err)
+		}
+		if err := createGofmtScript(inst.Version, inst.Dir, binDir); err != nil {
+			return added, fmt.Errorf("failed to create gofmt script for %s: %w", inst.Version, err)
+		}
+		added = append(added, inst.Version)
+	}
+	return added, nil
+}
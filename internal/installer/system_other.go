@@ -0,0 +1,13 @@
+//go:build !unix
+
+package installer
+
+import "io/fs"
+
+// setStoreUmask does nothing, there is no umask outside unix
+func setStoreUmask() {}
+
+// ownedByUs reports true, files carry no owner uid outside unix
+func ownedByUs(info fs.FileInfo) bool {
+	return true
+}
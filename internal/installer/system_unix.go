@@ -0,0 +1,21 @@
+//go:build unix
+
+package installer
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// setStoreUmask makes what goenv creates in the system store readable by every
+// user, a umask like 0077 would otherwise hide the SDKs from them
+func setStoreUmask() {
+	syscall.Umask(0022)
+}
+
+// ownedByUs reports whether the file belongs to the current user
+func ownedByUs(info fs.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Getuid()
+}
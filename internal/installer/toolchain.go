@@ -0,0 +1,66 @@
+package installer
+
+import (
+	"fmt"
+	goversion "go/version"
+	"os"
+
+	"github.com/hitzhangjie/goenv/internal/project"
+	"github.com/hitzhangjie/goenv/internal/version"
+)
+
+// ToolchainMismatch reports a module that needs a newer Go than the SDK about to run it
+type ToolchainMismatch struct {
+	Running  string // Version of the SDK
+	Required string // Minimum version from the go directive
+	Install  string // Version to install, the toolchain directive if it satisfies the go directive
+	Source   string // Path of the go.mod
+}
+
+func (m *ToolchainMismatch) Error() string {
+	return fmt.Sprintf("%s requires %s or later but %s is running, and GOTOOLCHAIN=local keeps go from switching;"+
+		" run 'goenv install %s' and use %s instead", m.Source, m.Required, m.Running, m.Install, m.Install)
+}
+
+// WrapperToolchainMismatch is FindToolchainMismatch for the environment the
+// wrappers of inst run go in
+func WrapperToolchainMismatch(inst *Installation, dir string) (*ToolchainMismatch, error) {
+	env, err := WrapperEnv(inst.Version, inst.Dir)
+	if err != nil {
+		return nil, err
+	}
+	overrides := make(map[string]string)
+	addWrapperEnv(overrides, env)
+	return FindToolchainMismatch(inst, dir, mergeEnv(os.Environ(), overrides)), nil
+}
+
+// FindToolchainMismatch checks whether the module of dir needs a newer Go than inst
+// while env, the environment inst is about to run in, forbids switching toolchains.
+// It returns nil if the versions fit, and leaves problems reading go.mod to the go command.
+func FindToolchainMismatch(inst *Installation, dir string, env []string) *ToolchainMismatch {
+	if lookupEnv(env, "GOTOOLCHAIN") != "local" {
+		return nil
+	}
+
+	// Development builds have no comparable version
+	running, _ := version.SplitVariant(inst.Version)
+	if !goversion.IsValid(running) {
+		return nil
+	}
+
+	req, err := project.ModuleRequirement(dir)
+	if err != nil || req == nil || !goversion.IsValid(req.Go) || goversion.Compare(req.Go, running) <= 0 {
+		return nil
+	}
+
+	install := (&project.Pin{Version: req.Go, Kind: project.KindGo}).Required()
+	if goversion.IsValid(req.Toolchain) && goversion.Compare(req.Toolchain, req.Go) >= 0 {
+		install = req.Toolchain
+	}
+	return &ToolchainMismatch{
+		Running:  inst.Version,
+		Required: req.Go,
+		Install:  install,
+		Source:   req.Source,
+	}
+}
@@ -0,0 +1,187 @@
+package installer
+
+import (
+	"debug/buildinfo"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+// Outcomes of syncing a tool
+const (
+	ToolInstalled = "installed"
+	ToolUpToDate  = "up to date"
+	ToolSkipped   = "skipped"
+	ToolFailed    = "failed"
+)
+
+// ToolResult is the outcome of syncing one tool for one Go version
+type ToolResult struct {
+	Version string // Go version
+	Tool    string // Binary name, e.g. "gopls"
+	Module  string // What was requested, e.g. "golang.org/x/tools/gopls@latest"
+	Status  string // One of the Tool constants
+	Err     error  // Why the install failed
+}
+
+var majorSuffixRegex = regexp.MustCompile(`^v[0-9]+$`)
+
+// toolBinary returns the name go install gives the binary of a package,
+// which leaves out major version suffixes like /v2
+func toolBinary(pkg string) string {
+	elem := path.Base(pkg)
+	if majorSuffixRegex.MatchString(elem) && strings.Contains(pkg, "/") {
+		return path.Base(path.Dir(pkg))
+	}
+	return elem
+}
+
+// SyncTools installs the tools of the manifest missing from the GOBIN of each
+// of installed, or built from another module version than the manifest asks for.
+// Every result is printed as it happens, failures do not stop the other tools.
+// The concurrency setting limits how many installs run at once.
+func SyncTools(installed []Installation) ([]ToolResult, error) {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+	if len(settings.Tools) == 0 {
+		return nil, fmt.Errorf("no tools configured, add them to the tools section of the config file")
+	}
+
+	type job struct {
+		inst *Installation
+		tool config.Tool
+	}
+	var jobs []job
+	for i := range installed {
+		for _, tool := range settings.Tools {
+			jobs = append(jobs, job{&installed[i], tool})
+		}
+	}
+
+	// Up to settings.Concurrency installs run at once, results keep the job order
+	results := make([]ToolResult, len(jobs))
+	slots := make(chan struct{}, settings.Concurrency)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, j := range jobs {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			result := syncTool(j.inst, &j.tool)
+			results[i] = result
+
+			mu.Lock()
+			fmt.Printf("%s: %s (%s) %s\n", result.Version, result.Tool, result.Module, result.Status)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	binDir, err := config.GetBinDir()
+	if err != nil {
+		return results, err
+	}
+	all, err := ListInstalled()
+	if err != nil {
+		return results, err
+	}
+	return results, fixToolShims(all, binDir)
+}
+
+// syncTool installs tool for inst unless it is already there
+func syncTool(inst *Installation, tool *config.Tool) ToolResult {
+	want := tool.VersionFor(inst.Version, versionLine(inst.Version))
+	result := ToolResult{
+		Version: inst.Version,
+		Tool:    toolBinary(tool.Path),
+		Module:  tool.Path + "@" + want,
+	}
+	if want == config.ToolSkip {
+		result.Status = ToolSkipped
+		return result
+	}
+
+	env, err := WrapperEnv(inst.Version, inst.Dir)
+	if err != nil {
+		result.Status, result.Err = ToolFailed, err
+		return result
+	}
+	overrides := make(map[string]string)
+	for _, e := range env {
+		overrides[e.Name] = e.Value
+	}
+
+	// Only exact versions can be compared with what the binary was built from
+	bin := filepath.Join(overrides["GOBIN"], result.Tool)
+	if want == "latest" {
+		if _, err := os.Stat(bin); err == nil {
+			result.Status = ToolUpToDate
+			return result
+		}
+	} else if info, err := buildinfo.ReadFile(bin); err == nil && info.Main.Version == want {
+		result.Status = ToolUpToDate
+		return result
+	}
+
+	cmd := exec.Command(filepath.Join(inst.Dir, "bin", "go"), "install", result.Module)
+	cmd.Env = mergeEnv(os.Environ(), overrides)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		result.Status = ToolFailed
+		result.Err = fmt.Errorf("%w\n%s", err, strings.TrimSpace(string(out)))
+		return result
+	}
+	result.Status = ToolInstalled
+	return result
+}
+
+// afterInstall syncs the tools into a newly installed version and deduplicates
+// the SDKs if the user opted in.
+// The installation itself succeeded, so failures are only reported.
+func afterInstall(version string) {
+	settings, err := config.LoadSettings()
+	if err != nil || installingToStore {
+		return
+	}
+	if settings.AutoSyncTools && len(settings.Tools) > 0 {
+		syncAfterInstall(version)
+	}
+	if settings.AutoDedupe {
+		fmt.Println("Deduplicating SDKs...")
+		result, err := Dedupe()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to deduplicate SDKs: %v\n", err)
+		} else if result.Files > 0 {
+			fmt.Printf("Linked %d duplicate files, saved %s\n", result.Files, FormatBytes(result.Saved))
+		}
+	}
+}
+
+// syncAfterInstall syncs the tools into a newly installed version
+func syncAfterInstall(version string) {
+	inst, err := FindInstalled(version)
+	if err != nil {
+		return
+	}
+
+	fmt.Printf("Syncing tools into %s\n", version)
+	results, err := SyncTools([]Installation{*inst})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to sync tools: %v\n", err)
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to install %s: %v\n", r.Module, r.Err)
+		}
+	}
+}
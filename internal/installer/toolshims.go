@@ -0,0 +1,175 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+// parseToolShim splits a tool shim name like gopls@go1.22.5 into tool and version.
+// Names never parse with an invalid pattern.
+func parseToolShim(pattern, name string) (tool, version string, ok bool) {
+	re, err := config.ParseToolShimPattern(pattern)
+	if err != nil {
+		return "", "", false
+	}
+	m := re.FindStringSubmatch(name)
+	if m == nil {
+		return "", "", false
+	}
+	return m[re.SubexpIndex("tool")], m[re.SubexpIndex("version")], true
+}
+
+// toolShimName returns the name of the shim for tool of version
+func toolShimName(pattern, tool, version string) string {
+	return strings.NewReplacer("{tool}", tool, "{version}", version).Replace(pattern)
+}
+
+// listTools returns the executables installed into the GOBIN of version
+func listTools(version string) ([]string, error) {
+	root, err := config.GetGoenvRoot()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(root, version, "bin"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var tools []string
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode()&0111 == 0 {
+			continue
+		}
+		tools = append(tools, entry.Name())
+	}
+	return tools, nil
+}
+
+// writeToolShim writes a wrapper at scriptPath running tool from the GOBIN of inst,
+// with the wrapper environment of inst and its go and gofmt first on PATH,
+// so tools that shell out to go, like gopls, use the same toolchain
+func writeToolShim(scriptPath, tool string, inst *Installation) error {
+	env, err := WrapperEnv(inst.Version, inst.Dir)
+	if err != nil {
+		return err
+	}
+	shimDir, err := EnsureShimDir(inst)
+	if err != nil {
+		return err
+	}
+
+	q, err := newWrapperQuoter(scriptPath)
+	if err != nil {
+		return err
+	}
+
+	assignments := []string{`PATH=` + q.quote(shimDir) + `:"$PATH"`}
+	var toolBin string
+	for _, e := range env {
+		assignments = append(assignments, q.assignment(e))
+		if e.Name == "GOBIN" {
+			toolBin = filepath.Join(e.Value, tool)
+		}
+	}
+	script := fmt.Sprintf(`#!/bin/bash
+%s%s exec %s "$@"
+`, q.prelude(), strings.Join(assignments, " "), q.quote(toolBin))
+
+	return writeWrapper(scriptPath, script)
+}
+
+// knownVersion reports whether goenv knows version, because it is installed or
+// still has its directory in the goenv root
+func knownVersion(version string) bool {
+	if _, err := FindInstalled(version); err == nil {
+		return true
+	}
+	root, err := config.GetGoenvRoot()
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(root, version))
+	return err == nil && info.IsDir()
+}
+
+// isToolShim reports whether path is a tool shim goenv wrote for tool of version:
+// a link to this goenv binary or a script running the tool from the GOBIN of version.
+// Other links are left alone, even to a goenv-named helper.
+func isToolShim(path, tool, version string) bool {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return false
+		}
+		exe, err := shimTarget()
+		return err == nil && target == exe
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	bin := "/" + filepath.Join(version, "bin", tool) + "'"
+	return strings.HasPrefix(string(data), "#!") && strings.Contains(string(data), bin)
+}
+
+// fixToolShims writes shims for the tools in the GOBIN of every installed version
+// and removes shims of tools or versions that are gone
+func fixToolShims(installed []Installation, binDir string) error {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]bool)
+	for i := range installed {
+		inst := &installed[i]
+		tools, err := listTools(inst.Version)
+		if err != nil {
+			return fmt.Errorf("failed to list tools of %s: %w", inst.Version, err)
+		}
+		for _, tool := range tools {
+			name := toolShimName(settings.ToolShimPattern, tool, inst.Version)
+			if err := writeToolShim(filepath.Join(binDir, name), tool, inst); err != nil {
+				return fmt.Errorf("failed to create tool shim %s: %w", name, err)
+			}
+			wanted[name] = true
+		}
+		if len(tools) > 0 {
+			fmt.Printf("Fixed tool shims for %s: %s\n", inst.Version, strings.Join(tools, ", "))
+		}
+	}
+
+	entries, err := os.ReadDir(binDir)
+	if err != nil {
+		return fmt.Errorf("failed to read bin directory: %w", err)
+	}
+	for _, entry := range entries {
+		if wanted[entry.Name()] {
+			continue
+		}
+		if _, _, ok := parseWrapperName(entry.Name()); ok {
+			continue
+		}
+		tool, version, ok := parseToolShim(settings.ToolShimPattern, entry.Name())
+		if !ok || !knownVersion(version) || !isToolShim(filepath.Join(binDir, entry.Name()), tool, version) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(binDir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to remove stale tool shim %s: %w", entry.Name(), err)
+		}
+		fmt.Printf("Removed stale tool shim %s\n", entry.Name())
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseToolShim(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		tool    string
+		version string
+		ok      bool
+	}{
+		{"{tool}@{version}", "gopls@go1.22.5", "gopls", "go1.22.5", true},
+		{"{tool}@{version}", "golangci-lint@go1.22.5+boring", "golangci-lint", "go1.22.5+boring", true},
+		{"{tool}@{version}", "dlv@godev-f52d441ca8", "dlv", "godev-f52d441ca8", true},
+		{"{tool}@{version}", "gopls@v0.14.2@go1.21.13", "gopls@v0.14.2", "go1.21.13", true},
+		{"{version}@{tool}", "go1.22.5@gopls", "gopls", "go1.22.5", true},
+		{"{tool}~{version}", "gopls~go1.22.5", "gopls", "go1.22.5", true},
+		{"{tool}@{version}", "gopls@1.22.5", "", "", false},
+		{"{tool}@{version}", "gopls-go1.22.5", "", "", false},
+		// The go and gofmt wrappers never look like tool shims
+		{"{tool}@{version}", "go1.22.5", "", "", false},
+		{"{tool}@{version}", "gofmt1.22.5", "", "", false},
+		{"{tool}@{version}", "godev-f52d441ca8", "", "", false},
+		{"{tool}@{version}", "go", "", "", false},
+		// Patterns config.Validate rejects never match
+		{"{tool}_at_{version}", "gopls_at_go1.22.5", "", "", false},
+		{"{tool}_{version}", "gopls_go1.22.5", "", "", false},
+		{"{version}.{tool}", "go1.22.5.gopls", "", "", false},
+	}
+	for _, tt := range tests {
+		tool, version, ok := parseToolShim(tt.pattern, tt.name)
+		if tool != tt.tool || version != tt.version || ok != tt.ok {
+			t.Errorf("parseToolShim(%q, %q) = %q, %q, %v, want %q, %q, %v",
+				tt.pattern, tt.name, tool, version, ok, tt.tool, tt.version, tt.ok)
+		}
+	}
+}
+
+func TestIsToolShim(t *testing.T) {
+	dir := t.TempDir()
+	shim := "#!/bin/bash\nexec '/home/u/.goenv/go1.22.5/bin/gopls' \"$@\"\n"
+	files := map[string]string{
+		"gopls@go1.22.5": shim,
+		"other@go1.22.5": "#!/bin/bash\nexec /usr/bin/other \"$@\"\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A user's helper that merely looks like the goenv binary
+	if err := os.Symlink("/usr/local/bin/goenv-helper", filepath.Join(dir, "helper@go1.22.5")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, tool string
+		want       bool
+	}{
+		{"gopls@go1.22.5", "gopls", true},
+		{"other@go1.22.5", "other", false},
+		{"helper@go1.22.5", "helper", false},
+		{"missing@go1.22.5", "missing", false},
+	}
+	for _, tt := range tests {
+		if got := isToolShim(filepath.Join(dir, tt.name), tt.tool, "go1.22.5"); got != tt.want {
+			t.Errorf("isToolShim(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
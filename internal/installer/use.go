@@ -0,0 +1,97 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+// GetDefault returns the version chosen with goenv use, or "" if there is none
+func GetDefault() (string, error) {
+	path, err := config.GetDefaultFile()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read default version: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// UseDefault makes plain go and gofmt in ~/.goenv/bin run inst,
+// with the same environment as its versioned wrapper
+func UseDefault(inst *Installation) error {
+	binDir, err := config.GetBinDir()
+	if err != nil {
+		return err
+	}
+	if err := config.EnsureDir(binDir); err != nil {
+		return fmt.Errorf("failed to create bin directory: %w", err)
+	}
+
+	path, err := config.GetDefaultFile()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(inst.Version+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write default version: %w", err)
+	}
+
+	return writeDefaultScripts(inst, binDir)
+}
+
+// ClearDefault removes the plain go and gofmt created by UseDefault
+func ClearDefault() error {
+	binDir, err := config.GetBinDir()
+	if err != nil {
+		return err
+	}
+	path, err := config.GetDefaultFile()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range []string{path, filepath.Join(binDir, "go"), filepath.Join(binDir, "gofmt")} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", p, err)
+		}
+	}
+	return nil
+}
+
+func writeDefaultScripts(inst *Installation, binDir string) error {
+	if err := writeGoScript(filepath.Join(binDir, "go"), inst.Version, inst.Dir); err != nil {
+		return fmt.Errorf("failed to create go script: %w", err)
+	}
+	if err := writeGofmtScript(filepath.Join(binDir, "gofmt"), inst.Dir); err != nil {
+		return fmt.Errorf("failed to create gofmt script: %w", err)
+	}
+	return nil
+}
+
+// fixDefaultScripts regenerates the plain go and gofmt, or removes them
+// if the default version is no longer installed
+func fixDefaultScripts(binDir string) error {
+	v, err := GetDefault()
+	if err != nil || v == "" {
+		return err
+	}
+
+	inst, err := FindInstalled(v)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: default version %s is no longer installed, removing go and gofmt.\n", v)
+		return ClearDefault()
+	}
+	if err := writeDefaultScripts(inst, binDir); err != nil {
+		return err
+	}
+	fmt.Printf("Fixed go and gofmt for default version %s\n", v)
+	return nil
+}
@@ -0,0 +1,210 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+	"github.com/hitzhangjie/goenv/internal/system"
+	"github.com/hitzhangjie/goenv/internal/version"
+)
+
+// InstallVariant builds a named variant of a Go release from source, e.g. "go1.22.5+boring".
+// experiments are passed to the build as GOEXPERIMENT so they become the toolchain's default,
+// patches are applied in order before building. A patch path may be a directory,
+// in which case its *.patch and *.diff files are applied in name order.
+func InstallVariant(name string, experiments, patches []string, bootstrap string) error {
+	if err := version.ValidateVariant(name); err != nil {
+		return err
+	}
+	base, _ := version.SplitVariant(name)
+
+	patchFiles, err := expandPatches(patches)
+	if err != nil {
+		return err
+	}
+
+	downloadsDir, err := config.GetDownloadsDir()
+	if err != nil {
+		return err
+	}
+	if err := config.EnsureDir(downloadsDir); err != nil {
+		return fmt.Errorf("failed to create downloads directory: %w", err)
+	}
+
+	sdkDir, err := config.GetSDKDir()
+	if err != nil {
+		return err
+	}
+	if err := config.EnsureDir(sdkDir); err != nil {
+		return fmt.Errorf("failed to create SDK directory: %w", err)
+	}
+
+	binDir, err := config.GetBinDir()
+	if err != nil {
+		return err
+	}
+	if err := config.EnsureDir(binDir); err != nil {
+		return fmt.Errorf("failed to create bin directory: %w", err)
+	}
+
+	buildDir, err := config.GetBuildDir()
+	if err != nil {
+		return err
+	}
+	if err := config.EnsureDir(buildDir); err != nil {
+		return fmt.Errorf("failed to create build directory: %w", err)
+	}
+
+	installDir := filepath.Join(sdkDir, name)
+	if _, err := os.Stat(filepath.Join(installDir, "bin", "go")); err == nil {
+		fmt.Printf("%s is already installed.\n", name)
+		return nil
+	}
+
+	boot, err := findBootstrap(bootstrap)
+	if err != nil {
+		return err
+	}
+
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return err
+	}
+
+	// Download the source archive (check if already exists first)
+	url := system.GetSourceURL(settings.Mirror, base)
+	tarballPath := filepath.Join(downloadsDir, filepath.Base(url))
+	if _, err := os.Stat(tarballPath); err == nil {
+		fmt.Printf("Found existing download: %s, skipping download.\n", tarballPath)
+	} else {
+		if err := downloadFile(url, tarballPath); err != nil {
+			return fmt.Errorf("failed to download: %w", err)
+		}
+	}
+
+	tree := filepath.Join(buildDir, name)
+	if err := os.RemoveAll(tree); err != nil {
+		return fmt.Errorf("failed to remove stale build directory: %w", err)
+	}
+	defer os.RemoveAll(tree)
+
+	if err := extractTarball(tarballPath, tree); err != nil {
+		return fmt.Errorf("failed to extract: %w", err)
+	}
+
+	var applied []Patch
+	for _, patch := range patchFiles {
+		fmt.Printf("Applying patch %s...\n", patch)
+		p, err := applyPatch(tree, patch)
+		if err != nil {
+			return fmt.Errorf("failed to apply patch %s: %w", patch, err)
+		}
+		applied = append(applied, *p)
+	}
+
+	var extraEnv []string
+	if len(experiments) > 0 {
+		extraEnv = append(extraEnv, "GOEXPERIMENT="+strings.Join(experiments, ","))
+	}
+
+	fmt.Printf("Building %s with bootstrap %s...\n", name, boot.Version)
+	if err := buildFromSource(tree, boot.Dir, extraEnv); err != nil {
+		return fmt.Errorf("failed to build: %w", err)
+	}
+
+	if err := os.Rename(tree, installDir); err != nil {
+		return fmt.Errorf("failed to move build into SDK directory: %w", err)
+	}
+
+	if err := createGoScript(name, installDir, binDir); err != nil {
+		return fmt.Errorf("failed to create go script: %w", err)
+	}
+	if err := createGofmtScript(name, installDir, binDir); err != nil {
+		return fmt.Errorf("failed to create gofmt script: %w", err)
+	}
+
+	receipt := &Receipt{
+		Version:     name,
+		Source:      SourceVariant,
+		URL:         url,
+		Bootstrap:   boot.Version,
+		BaseVersion: base,
+		Experiments: experiments,
+		Patches:     applied,
+		InstalledAt: time.Now(),
+	}
+	if err := SaveReceipt(receipt); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully installed %s\n", name)
+	fmt.Printf("Use '%s' to run this version of Go\n", name)
+	afterInstall(name)
+	return nil
+}
+
+// expandPatches resolves patch arguments to an ordered list of patch files
+func expandPatches(patches []string) ([]string, error) {
+	var files []string
+	for _, p := range patches {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read patch: %w", err)
+		}
+		if !info.IsDir() {
+			files = append(files, abs)
+			continue
+		}
+
+		entries, err := os.ReadDir(abs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read patch directory: %w", err)
+		}
+		var series []string
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if !entry.IsDir() && (ext == ".patch" || ext == ".diff") {
+				series = append(series, filepath.Join(abs, entry.Name()))
+			}
+		}
+		if len(series) == 0 {
+			return nil, fmt.Errorf("no *.patch or *.diff files found in %s", abs)
+		}
+		sort.Strings(series)
+		files = append(files, series...)
+	}
+	return files, nil
+}
+
+// applyPatch applies a -p1 patch to the source tree and returns its identity for the receipt
+func applyPatch(tree, patchFile string) (*Patch, error) {
+	data, err := os.ReadFile(patchFile)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+
+	cmd := exec.Command("git", "apply", "-p1", patchFile)
+	cmd.Dir = tree
+	// Keep git from treating a repository above the build tree as the patch target
+	cmd.Env = append(os.Environ(), "GIT_CEILING_DIRECTORIES="+filepath.Dir(tree))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	return &Patch{Name: filepath.Base(patchFile), SHA256: hex.EncodeToString(sum[:])}, nil
+}
@@ -0,0 +1,269 @@
+package project
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+	"github.com/hitzhangjie/goenv/internal/version"
+	"golang.org/x/mod/modfile"
+)
+
+// Where a pinned version comes from
+const (
+	KindEnv          = "env"
+	KindGoVersion    = ".go-version"
+	KindToolVersions = ".tool-versions"
+	KindToolchain    = "toolchain"
+	KindGo           = "go"
+)
+
+// PinFiles are the files Resolve looks for in every directory
+var PinFiles = []string{KindGoVersion, KindToolVersions, "go.mod"}
+
+// Pin is a Go version requested for a directory
+type Pin struct {
+	Version string // e.g. "go1.22.5", or "go1.22" for a whole minor line
+	Kind    string // One of the Kind constants
+	Source  string // File (or variable) that requested the version
+}
+
+// Resolve returns the Go version pinned for dir, or nil if nothing is pinned.
+// GOENV_VERSION takes precedence. Otherwise each directory from dir up to the root
+// is checked, in order, for .go-version, .tool-versions, and the toolchain and
+// go directives of go.mod; the nearest directory with any of them wins.
+func Resolve(dir string) (*Pin, error) {
+	if v := os.Getenv(config.VersionEnv); v != "" {
+		return &Pin{Version: version.NormalizeVersion(v), Kind: KindEnv, Source: config.VersionEnv}, nil
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		pin, err := resolveDir(dir)
+		if err != nil || pin != nil {
+			return pin, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+func resolveDir(dir string) (*Pin, error) {
+	path := filepath.Join(dir, KindGoVersion)
+	if v, err := ReadVersionFile(path); err != nil {
+		return nil, err
+	} else if v != "" {
+		return &Pin{Version: version.NormalizeVersion(v), Kind: KindGoVersion, Source: path}, nil
+	}
+
+	path = filepath.Join(dir, KindToolVersions)
+	if v, err := ReadToolVersions(path); err != nil {
+		return nil, err
+	} else if v != "" {
+		return &Pin{Version: version.NormalizeVersion(v), Kind: KindToolVersions, Source: path}, nil
+	}
+
+	path = filepath.Join(dir, "go.mod")
+	f, err := parseGoMod(path)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	if f.Toolchain != nil && f.Toolchain.Name != "default" {
+		return &Pin{Version: f.Toolchain.Name, Kind: KindToolchain, Source: path}, nil
+	}
+	if f.Go != nil {
+		return &Pin{Version: "go" + f.Go.Version, Kind: KindGo, Source: path}, nil
+	}
+	return nil, nil
+}
+
+// parseGoMod parses the go.mod at path, or returns nil if there is none
+func parseGoMod(path string) (*modfile.File, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// The lax parser used for dependencies skips the toolchain directive
+	f, err := modfile.Parse(path, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Requirement is the Go version a module needs, from its go and toolchain directives
+type Requirement struct {
+	Go        string // Minimum version from the go directive, e.g. "go1.22" or "go1.22.3"
+	Toolchain string // Preferred toolchain, e.g. "go1.23.2", or "" if there is none
+	Source    string // Path of the go.mod
+}
+
+// ModuleRequirement returns the requirement of the module dir belongs to, the nearest
+// go.mod from dir up to the root, or nil if dir is not in a module
+func ModuleRequirement(dir string) (*Requirement, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		path := filepath.Join(dir, "go.mod")
+		f, err := parseGoMod(path)
+		if err != nil {
+			return nil, err
+		}
+		if f != nil {
+			req := &Requirement{Source: path}
+			if f.Go != nil {
+				req.Go = "go" + f.Go.Version
+			}
+			if f.Toolchain != nil && f.Toolchain.Name != "default" {
+				req.Toolchain = f.Toolchain.Name
+			}
+			return req, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// ReadVersionFile returns the version in a .go-version file, or "" if there is none
+func ReadVersionFile(path string) (string, error) {
+	var v string
+	err := scanVersionFile(path, func(line string) bool {
+		v = line
+		return true
+	})
+	return v, err
+}
+
+// ReadToolVersions returns the Go version in an asdf .tool-versions file, or "" if there is none
+func ReadToolVersions(path string) (string, error) {
+	var v string
+	err := scanVersionFile(path, func(line string) bool {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && (fields[0] == "golang" || fields[0] == "go") {
+			v = fields[1]
+			return true
+		}
+		return false
+	})
+	return v, err
+}
+
+// scanVersionFile calls match for every non-empty line with comments stripped until it returns true
+func scanVersionFile(path string, match func(line string) bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line != "" && match(line) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+var lineRegex = regexp.MustCompile(`^go\d+\.\d+$`)
+
+// IsLine reports whether the pin names a minor line (e.g. "go1.22") rather than a release
+func (p *Pin) IsLine() bool {
+	return lineRegex.MatchString(p.Version)
+}
+
+// Required returns the version to install to satisfy the pin.
+// Since Go 1.21 the first release of a line is named goX.Y.0, for older lines goX.Y.
+func (p *Pin) Required() string {
+	if !p.IsLine() {
+		return p.Version
+	}
+	if v, err := version.ParseVersion(p.Version); err == nil && (v.Major > 1 || v.Minor >= 21) {
+		return p.Version + ".0"
+	}
+	return p.Version
+}
+
+// Match picks the installed version satisfying the pin, or "" if there is none.
+// Lines and go directives accept the newest installed release of their line
+// (for go directives at least the required one), other pins must match exactly.
+func (p *Pin) Match(installed []string) string {
+	want, err := version.ParseVersion(p.Version)
+	if err != nil {
+		// Variants and development builds are matched by name
+		for _, v := range installed {
+			if v == p.Version {
+				return v
+			}
+		}
+		return ""
+	}
+
+	var best *version.Version
+	for _, name := range installed {
+		v, err := version.ParseVersion(name)
+		if err != nil {
+			continue
+		}
+		switch {
+		case p.Kind == KindGo:
+			if v.GetMajorMinor() != want.GetMajorMinor() || v.Compare(want) < 0 {
+				continue
+			}
+		case p.IsLine():
+			if v.GetMajorMinor() != want.GetMajorMinor() {
+				continue
+			}
+		default:
+			if v.Compare(want) != 0 {
+				continue
+			}
+		}
+		if best == nil || v.Compare(best) > 0 {
+			best = v
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.Tag
+}
+
+// Describe returns where the pin comes from, e.g. "/src/app/go.mod toolchain directive"
+func (p *Pin) Describe() string {
+	switch p.Kind {
+	case KindEnv:
+		return p.Source + " environment variable"
+	case KindToolchain, KindGo:
+		return fmt.Sprintf("%s %s directive", p.Source, p.Kind)
+	}
+	return p.Source
+}
@@ -0,0 +1,119 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hitzhangjie/goenv/internal/config"
+)
+
+// writeFiles creates the files below root, keyed by slash-separated path
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".go-version":              "# the default of the tree\ngo1.21\n",
+		"mod/go.mod":               "module example.com/mod\n\ngo 1.22.1\n\ntoolchain go1.22.5\n",
+		"mod/tools/.tool-versions": "nodejs 20.1.0\ngolang 1.20.3 # asdf\n",
+		"mod/other/.tool-versions": "nodejs 20.1.0\n",
+		"mod/empty/.go-version":    "# nothing pinned\n",
+		"both/go.mod":              "module example.com/both\n\ngo 1.22.1\n",
+		"both/.go-version":         "go1.22.5+boring\n",
+		"plain/go.mod":             "module example.com/plain\n\ngo 1.22.1\n\ntoolchain default\n",
+	})
+
+	tests := []struct {
+		dir     string
+		version string
+		kind    string
+		source  string
+	}{
+		{".", "go1.21", KindGoVersion, ".go-version"},
+		{"mod", "go1.22.5", KindToolchain, "mod/go.mod"},
+		{"mod/tools", "go1.20.3", KindToolVersions, "mod/tools/.tool-versions"},
+		// Files without a Go version do not stop the search
+		{"mod/other", "go1.22.5", KindToolchain, "mod/go.mod"},
+		{"mod/empty", "go1.22.5", KindToolchain, "mod/go.mod"},
+		// .go-version wins over go.mod in the same directory
+		{"both", "go1.22.5+boring", KindGoVersion, "both/.go-version"},
+		{"plain", "go1.22.1", KindGo, "plain/go.mod"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			t.Setenv(config.VersionEnv, "")
+			pin, err := Resolve(filepath.Join(root, tt.dir))
+			if err != nil {
+				t.Fatal(err)
+			}
+			source := filepath.Join(root, filepath.FromSlash(tt.source))
+			if pin == nil || pin.Version != tt.version || pin.Kind != tt.kind || pin.Source != source {
+				t.Errorf("Resolve = %+v, want %s from %s (%s)", pin, tt.version, source, tt.kind)
+			}
+		})
+	}
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv(config.VersionEnv, "1.19.13")
+		pin, err := Resolve(filepath.Join(root, "mod"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pin == nil || pin.Version != "go1.19.13" || pin.Kind != KindEnv {
+			t.Errorf("Resolve = %+v, want go1.19.13 from %s", pin, config.VersionEnv)
+		}
+	})
+}
+
+func TestPinMatch(t *testing.T) {
+	installed := []string{"go1.20.14", "go1.22.1", "go1.22.5", "go1.22.5+boring", "go1.23.0", "godev-f52d441ca8"}
+	tests := []struct {
+		pin  Pin
+		want string
+	}{
+		{Pin{Version: "go1.22.1", Kind: KindGoVersion}, "go1.22.1"},
+		{Pin{Version: "go1.22.3", Kind: KindGoVersion}, ""},
+		// Lines pick the newest release of the line
+		{Pin{Version: "go1.22", Kind: KindGoVersion}, "go1.22.5"},
+		{Pin{Version: "go1.21", Kind: KindToolVersions}, ""},
+		// go directives accept newer releases of the same line only
+		{Pin{Version: "go1.22.2", Kind: KindGo}, "go1.22.5"},
+		{Pin{Version: "go1.22.6", Kind: KindGo}, ""},
+		{Pin{Version: "go1.22.5", Kind: KindToolchain}, "go1.22.5"},
+		// Variants and development builds match by name
+		{Pin{Version: "go1.22.5+boring", Kind: KindGoVersion}, "go1.22.5+boring"},
+		{Pin{Version: "go1.22.5+other", Kind: KindGoVersion}, ""},
+		{Pin{Version: "godev-f52d441ca8", Kind: KindEnv}, "godev-f52d441ca8"},
+	}
+	for _, tt := range tests {
+		if got := tt.pin.Match(installed); got != tt.want {
+			t.Errorf("%s pin %s: Match = %q, want %q", tt.pin.Kind, tt.pin.Version, got, tt.want)
+		}
+	}
+}
+
+func TestPinRequired(t *testing.T) {
+	tests := []struct{ version, want string }{
+		{"go1.22", "go1.22.0"},
+		{"go1.21", "go1.21.0"},
+		{"go1.20", "go1.20"},
+		{"go1.22.5", "go1.22.5"},
+	}
+	for _, tt := range tests {
+		if got := (&Pin{Version: tt.version}).Required(); got != tt.want {
+			t.Errorf("Required(%s) = %s, want %s", tt.version, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Markers delimiting the block goenv init --install adds to rc files
+const (
+	blockStart = "# >>> goenv init >>>"
+	blockEnd   = "# <<< goenv init <<<"
+)
+
+// Init returns the snippet to source in the shell: PATH setup for binDir,
+// completion registration and, if hook is set, the directory change hook.
+func Init(name, goenv, binDir string, hook bool) string {
+	q := Quote(name, goenv)
+	var b strings.Builder
+
+	switch name {
+	case Fish:
+		fmt.Fprintf(&b, "contains -- %s $PATH; or set -gx PATH %s $PATH\n", Quote(name, binDir), Quote(name, binDir))
+		fmt.Fprintf(&b, "%s completion fish | source\n", q)
+	default:
+		fmt.Fprintf(&b, "case \":$PATH:\" in\n  *:%s:*) ;;\n  *) export PATH=%s:\"$PATH\" ;;\nesac\n", Quote(name, binDir), Quote(name, binDir))
+		if name == Zsh {
+			// Completions register through compdef as soon as they are sourced, which needs compinit
+			b.WriteString("(( $+functions[compdef] )) || { autoload -Uz compinit && compinit; }\n")
+		}
+		fmt.Fprintf(&b, "source <(%s completion %s)\n", q, name)
+	}
+
+	if hook {
+		b.WriteString(Hook(name, goenv))
+	}
+	return b.String()
+}
+
+// RCFile returns the startup file goenv init --install edits for the shell
+func RCFile(name string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	switch name {
+	case Zsh:
+		if zdotdir := os.Getenv("ZDOTDIR"); zdotdir != "" {
+			return filepath.Join(zdotdir, ".zshrc"), nil
+		}
+		return filepath.Join(homeDir, ".zshrc"), nil
+	case Fish:
+		configHome := os.Getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			configHome = filepath.Join(homeDir, ".config")
+		}
+		return filepath.Join(configHome, "fish", "config.fish"), nil
+	}
+	return filepath.Join(homeDir, ".bashrc"), nil
+}
+
+// RCBlock returns the marked block loading goenv init from an rc file
+func RCBlock(name, goenv string, hook bool) string {
+	args := "init " + name
+	if hook {
+		args += " --hook"
+	}
+	line := fmt.Sprintf("eval \"$(%s %s)\"", Quote(name, goenv), args)
+	if name == Fish {
+		line = fmt.Sprintf("%s %s | source", Quote(name, goenv), args)
+	}
+	return blockStart + "\n" + line + "\n" + blockEnd + "\n"
+}
+
+// InstallBlock writes block to the rc file at path, replacing a previously
+// installed block. It reports whether the file changed.
+func InstallBlock(path, block string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	content := removeBlock(string(data))
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += block
+	if content == string(data) {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, []byte(content), 0644)
+}
+
+// RemoveBlock removes an installed block from the rc file at path.
+// It reports whether the file changed.
+func RemoveBlock(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	content := removeBlock(string(data))
+	if content == string(data) {
+		return false, nil
+	}
+	return true, os.WriteFile(path, []byte(content), 0644)
+}
+
+func removeBlock(content string) string {
+	start := strings.Index(content, blockStart)
+	if start < 0 {
+		return content
+	}
+	end := strings.Index(content[start:], blockEnd)
+	if end < 0 {
+		return content
+	}
+	end += start + len(blockEnd)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return content[:start] + content[end:]
+}
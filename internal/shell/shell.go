@@ -0,0 +1,105 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Supported shells
+const (
+	Bash = "bash"
+	Zsh  = "zsh"
+	Fish = "fish"
+)
+
+// Detect returns the supported shell named by $SHELL
+func Detect() (string, error) {
+	name := filepath.Base(os.Getenv("SHELL"))
+	if err := Check(name); err != nil {
+		return "", fmt.Errorf("cannot detect shell from $SHELL=%q, pass bash, zsh or fish explicitly", os.Getenv("SHELL"))
+	}
+	return name, nil
+}
+
+// Check returns an error if the shell is not supported
+func Check(name string) error {
+	switch name {
+	case Bash, Zsh, Fish:
+		return nil
+	}
+	return fmt.Errorf("unsupported shell %q, want %s, %s or %s", name, Bash, Zsh, Fish)
+}
+
+// Quote quotes s as a single word for the shell
+func Quote(name, s string) string {
+	if name == Fish {
+		// In fish only \ and ' are special inside single quotes
+		s = strings.ReplaceAll(s, `\`, `\\`)
+		return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Export returns a statement setting an exported environment variable
+func Export(name, key, value string) string {
+	if name == Fish {
+		return fmt.Sprintf("set -gx %s %s", key, Quote(name, value))
+	}
+	return fmt.Sprintf("export %s=%s", key, Quote(name, value))
+}
+
+// Unset returns a statement removing an environment variable
+func Unset(name, key string) string {
+	if name == Fish {
+		return "set -e " + key
+	}
+	return "unset " + key
+}
+
+// SetPath returns a statement setting PATH to the given entries
+func SetPath(name string, entries []string) string {
+	if name == Fish {
+		quoted := make([]string, len(entries))
+		for i, e := range entries {
+			quoted[i] = Quote(name, e)
+		}
+		return "set -gx PATH " + strings.Join(quoted, " ")
+	}
+	return Export(name, "PATH", strings.Join(entries, string(os.PathListSeparator)))
+}
+
+// Hook returns code that runs `goenv hook-env` before every prompt, which only
+// changes the environment if the version pinned for the working directory did.
+// goenv is the absolute path of the goenv binary.
+func Hook(name, goenv string) string {
+	q := Quote(name, goenv)
+	switch name {
+	case Zsh:
+		return fmt.Sprintf(`_goenv_hook() {
+  eval "$(%s hook-env zsh)"
+}
+typeset -ag precmd_functions
+if (( ! ${precmd_functions[(I)_goenv_hook]} )); then
+  precmd_functions=(_goenv_hook $precmd_functions)
+fi
+_goenv_hook
+`, q)
+	case Fish:
+		return fmt.Sprintf(`function _goenv_hook --on-event fish_prompt
+    %s hook-env fish | source
+end
+_goenv_hook
+`, q)
+	}
+	return fmt.Sprintf(`_goenv_hook() {
+  local previous_exit_status=$?
+  eval "$(%s hook-env bash)"
+  return $previous_exit_status
+}
+if [[ ";${PROMPT_COMMAND:-};" != *";_goenv_hook;"* ]]; then
+  PROMPT_COMMAND="_goenv_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+`, q)
+}
@@ -2,7 +2,9 @@ package system
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 )
@@ -27,6 +29,26 @@ func GetGOARCH() (string, error) {
 	return runtime.GOARCH, nil
 }
 
+// GetModCache returns the module cache directory of the go command on PATH
+func GetModCache() (string, error) {
+	if modcache, err := getFromGoEnv("GOMODCACHE"); err == nil && modcache != "" {
+		return modcache, nil
+	}
+	// Fallback to the documented default
+	if modcache := os.Getenv("GOMODCACHE"); modcache != "" {
+		return modcache, nil
+	}
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		gopath = filepath.Join(homeDir, "go")
+	}
+	return filepath.Join(filepath.SplitList(gopath)[0], "pkg", "mod"), nil
+}
+
 func getFromGoEnv(key string) (string, error) {
 	cmd := exec.Command("go", "env", key)
 	output, err := cmd.Output()
@@ -36,12 +58,18 @@ func getFromGoEnv(key string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
-// GetDownloadURL constructs the download URL for a Go version
-func GetDownloadURL(version, goos, goarch string) string {
+// GetDownloadURL constructs the download URL for a Go version on mirror, e.g. https://dl.google.com/go/
+func GetDownloadURL(mirror, version, goos, goarch string) string {
 	// Remove "go" prefix if present
 	ver := version
 	if strings.HasPrefix(ver, "go") {
 		ver = ver[2:]
 	}
-	return fmt.Sprintf("https://dl.google.com/go/go%s.%s-%s.tar.gz", ver, goos, goarch)
+	return fmt.Sprintf("%s/go%s.%s-%s.tar.gz", strings.TrimSuffix(mirror, "/"), ver, goos, goarch)
+}
+
+// GetSourceURL constructs the download URL for the source archive of a Go version on mirror
+func GetSourceURL(mirror, version string) string {
+	ver := strings.TrimPrefix(version, "go")
+	return fmt.Sprintf("%s/go%s.src.tar.gz", strings.TrimSuffix(mirror, "/"), ver)
 }
@@ -165,3 +165,24 @@ func NormalizeVersion(version string) string {
 	}
 	return version
 }
+
+var variantRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// SplitVariant splits a variant name such as "go1.22.5+boring" into its
+// base version ("go1.22.5") and variant ("boring"). variant is empty for plain versions.
+func SplitVariant(name string) (base, variant string) {
+	base, variant, _ = strings.Cut(name, "+")
+	return base, variant
+}
+
+// ValidateVariant checks that name is a release version followed by a variant suffix
+func ValidateVariant(name string) error {
+	base, variant := SplitVariant(name)
+	if _, err := ParseVersion(base); err != nil {
+		return err
+	}
+	if !variantRegex.MatchString(variant) {
+		return fmt.Errorf("invalid variant name %q: use letters, digits, '.', '_' or '-' after '+'", variant)
+	}
+	return nil
+}
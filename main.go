@@ -1,14 +1,28 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/hitzhangjie/goenv/internal/cmd"
+	"github.com/hitzhangjie/goenv/internal/installer"
 )
 
 func main() {
+	// Invoked through a wrapper link such as go1.22.5, become that go command
+	if installer.IsShim(os.Args[0]) {
+		err := installer.RunShim(os.Args)
+		fmt.Fprintf(os.Stderr, "goenv: %v\n", err)
+		os.Exit(1)
+	}
+
 	if err := cmd.Execute(); err != nil {
+		// Commands run on behalf of the user report their own errors, just pass on the status
+		var exitErr interface{ ExitCode() int }
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}